@@ -13,6 +13,7 @@ func main() {
 	app := config.NewFiber(viperConfig)
 	jwt := config.NewJwt(viperConfig)
 	emailClient := config.NewEmail(viperConfig, log)
+	oidcRegistry := config.NewOidc(viperConfig)
 
 	migrationErr := config.NewDatabaseMigration(db, log)
 	if migrationErr != nil {
@@ -27,6 +28,7 @@ func main() {
 		Config:      viperConfig,
 		EmailClient: emailClient,
 		JWTService:  jwt,
+		Oidc:        oidcRegistry,
 	})
 
 	appPort := viperConfig.GetInt("server.port")
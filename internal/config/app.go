@@ -6,6 +6,7 @@ import (
 	"codename-rl/internal/delivery/http/route"
 	"codename-rl/internal/pkg/auth"
 	"codename-rl/internal/pkg/email"
+	"codename-rl/internal/pkg/oidc"
 	"codename-rl/internal/repository"
 	"codename-rl/internal/usecase"
 
@@ -23,6 +24,7 @@ type BootstrapConfig struct {
 	Validate    *validator.Validate
 	JWTService  *auth.JwtService
 	EmailClient *email.Client
+	Oidc        *oidc.Registry
 	Config      *viper.Viper
 }
 
@@ -30,42 +32,77 @@ func Bootstrap(config *BootstrapConfig) {
 	// setup repositories
 	userRepository := repository.NewUserRepository(config.Log)
 	otpRepository := repository.NewOtpRepository(config.Log)
+	sessionRepository := repository.NewSessionRepository(config.Log)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(config.Log)
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository(config.Log)
+	challengeRepository := repository.NewTwoFactorChallengeRepository(config.Log)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(config.Log)
+	accessTokenRepository := repository.NewAccessTokenRepository(config.Log)
+	oidcStateRepository := repository.NewOidcStateRepository(config.Log)
+	oidcIdentityRepository := repository.NewOidcIdentityRepository(config.Log)
 	tagRepository := repository.NewTagRepository(config.Log)
 	personRepository := repository.NewPersonRepository(config.Log)
 	relationshipRepository := repository.NewRelationshipRepository(config.Log)
 	phoneRepository := repository.NewPhoneRepository(config.Log)
 	importantDateRepository := repository.NewImportantDateRepository(config.Log)
+	addressBookRepository := repository.NewAddressBookRepository(config.Log)
+	addressBookInvitationRepository := repository.NewAddressBookInvitationRepository(config.Log)
+	searchRepository := repository.NewSearchRepository(config.Log)
+	smartListRepository := repository.NewSmartListRepository(config.Log)
 
 	// setup use cases
-	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validate, userRepository, otpRepository, config.JWTService)
+	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validate, userRepository, otpRepository, sessionRepository, refreshTokenRepository, recoveryCodeRepository, challengeRepository, loginAttemptRepository, accessTokenRepository, config.EmailClient, config.JWTService)
+	twoFactorUseCase := usecase.NewTwoFactorUseCase(config.DB, config.Log, config.Validate, userRepository, recoveryCodeRepository, loginAttemptRepository)
+	oidcUseCase := usecase.NewOidcUseCase(config.DB, config.Log, config.Validate, config.Oidc, oidcStateRepository, oidcIdentityRepository, userRepository, sessionRepository, userUseCase)
+	accessTokenUseCase := usecase.NewAccessTokenUseCase(config.DB, config.Log, config.Validate, accessTokenRepository)
 	otpUseCase := usecase.NewOtpUseCase(config.DB, config.Log, config.Validate, otpRepository, userRepository, config.EmailClient, config.JWTService)
-	tagUseCase := usecase.NewTagUseCase(config.DB, config.Log, config.Validate, tagRepository, config.JWTService)
-	personUseCase := usecase.NewPersonUseCase(config.DB, config.Log, config.Validate, personRepository, config.JWTService)
-	relationshipUseCase := usecase.NewRelationshipUseCase(config.DB, config.Log, config.Validate, relationshipRepository, config.JWTService)
-	phoneUseCase := usecase.NewPhoneUseCase(config.DB, config.Log, config.Validate, phoneRepository, config.JWTService)
-	importantDateUseCase := usecase.NewImportantDateUseCase(config.DB, config.Log, config.Validate, importantDateRepository, config.JWTService)
+	tagUseCase := usecase.NewTagUseCase(config.DB, config.Log, config.Validate, tagRepository, addressBookRepository, config.JWTService)
+	personUseCase := usecase.NewPersonUseCase(config.DB, config.Log, config.Validate, personRepository, addressBookRepository, config.JWTService)
+	relationshipUseCase := usecase.NewRelationshipUseCase(config.DB, config.Log, config.Validate, relationshipRepository, addressBookRepository, config.JWTService)
+	phoneUseCase := usecase.NewPhoneUseCase(config.DB, config.Log, config.Validate, phoneRepository, addressBookRepository, config.JWTService)
+	addressBookUseCase := usecase.NewAddressBookUseCase(config.DB, config.Log, config.Validate, addressBookRepository, addressBookInvitationRepository, userRepository, config.EmailClient)
+	importantDateUseCase := usecase.NewImportantDateUseCase(config.DB, config.Log, config.Validate, importantDateRepository, addressBookRepository, config.JWTService)
+	searchUseCase := usecase.NewSearchUseCase(config.DB, config.Log, config.Validate, searchRepository)
+	smartListUseCase := usecase.NewSmartListUseCase(config.DB, config.Log, config.Validate, smartListRepository, personRepository)
 
 	// setup controller
 	userController := handler.NewUserController(userUseCase, config.Log)
 	otpController := handler.NewOtpController(otpUseCase, config.Log)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorUseCase, config.Log)
 	tagHandler := handler.NewTagHandler(tagUseCase, config.Log)
 	personHandler := handler.NewPersonHandler(personUseCase, config.Log)
 	relationshipHandler := handler.NewRelationshipHandler(relationshipUseCase, config.Log)
 	phoneHandler := handler.NewPhoneHandler(phoneUseCase, config.Log)
 	importantDateHandler := handler.NewImportantDateHandler(importantDateUseCase, config.Log)
+	addressBookHandler := handler.NewAddressBookHandler(addressBookUseCase, config.Log)
+	searchHandler := handler.NewSearchHandler(searchUseCase, config.Log)
+	smartListHandler := handler.NewSmartListHandler(smartListUseCase, config.Log)
+	jwksHandler := handler.NewJwksHandler(config.JWTService, config.Log)
+	accessTokenHandler := handler.NewAccessTokenHandler(accessTokenUseCase, config.Log)
+	oidcHandler := handler.NewOidcHandler(oidcUseCase, config.Log)
 
 	// setup middleware
-	authMiddleware := middleware.NewAuth(userUseCase)
+	authMiddleware := middleware.NewAuth(userUseCase, &middleware.VerificationPolicy{
+		RequireVerifiedEmail: config.Config.GetBool("auth.require_verified_email"),
+		AllowedRoutes:        config.Config.GetStringSlice("auth.unverified_routes"),
+	})
 
 	routeConfig := route.Config{
 		App:                     config.App,
 		UserController:          userController,
 		OtpController:           otpController,
+		TwoFactorController:     twoFactorHandler,
 		TagController:           tagHandler,
 		PersonController:        personHandler,
 		RelationshipController:  relationshipHandler,
 		PhoneController:         phoneHandler,
 		ImportantDateController: importantDateHandler,
+		AddressBookController:   addressBookHandler,
+		SearchController:        searchHandler,
+		SmartListController:     smartListHandler,
+		JwksController:          jwksHandler,
+		AccessTokenController:   accessTokenHandler,
+		OidcController:          oidcHandler,
 		AuthMiddleware:          authMiddleware,
 	}
 	routeConfig.Setup()
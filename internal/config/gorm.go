@@ -26,6 +26,7 @@ func NewDatabase(viper *viper.Viper, log *logrus.Logger) *gorm.DB {
 		username, password, host, port, database,
 	)
 	db, err := gorm.Open(postgres.Open(url), &gorm.Config{
+		TranslateError: true,
 		Logger: logger.New(&logrusWriter{Logger: log}, logger.Config{
 			SlowThreshold:             time.Second * 5,
 			Colorful:                  false,
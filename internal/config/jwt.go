@@ -2,11 +2,31 @@ package config
 
 import (
 	"codename-rl/internal/pkg/auth"
+	"fmt"
 
 	"github.com/spf13/viper"
 )
 
 func NewJwt(config *viper.Viper) *auth.JwtService {
 	secret := config.GetString("jwt.secret")
-	return auth.NewJwtService(secret)
+
+	var keyConfigs []auth.KeyConfig
+	if err := config.UnmarshalKey("jwt.keys", &keyConfigs); err != nil {
+		panic(fmt.Errorf("fatal error loading jwt.keys: %w", err))
+	}
+
+	keys := make([]*auth.SigningKey, 0, len(keyConfigs))
+	for _, keyConfig := range keyConfigs {
+		key, err := auth.LoadSigningKey(keyConfig)
+		if err != nil {
+			panic(fmt.Errorf("fatal error loading jwt key: %w", err))
+		}
+		keys = append(keys, key)
+	}
+
+	service, err := auth.NewJwtService(secret, keys...)
+	if err != nil {
+		panic(fmt.Errorf("fatal error loading jwt keys: %w", err))
+	}
+	return service
 }
@@ -11,17 +11,77 @@ import (
 func NewDatabaseMigration(db *gorm.DB, log *logrus.Logger) error {
 	log.Info("Running database migrations...")
 
+	if err := repository.DropGlobalNameUniques(db); err != nil {
+		log.Fatalf("Failed to drop global name constraints: %v", err)
+		return err
+	}
+
+	if err := repository.DropGlobalPhoneNumberUnique(db); err != nil {
+		log.Fatalf("Failed to drop global phone number constraint: %v", err)
+		return err
+	}
+
+	if err := repository.DropPersonChildForeignKeys(db); err != nil {
+		log.Fatalf("Failed to drop person foreign keys: %v", err)
+		return err
+	}
+
 	if err := repository.AutoMigrate(
 		db,
 		&entity.User{},
 		&entity.Otp{},
+		&entity.Session{},
+		&entity.RefreshToken{},
+		&entity.RecoveryCode{},
+		&entity.TwoFactorChallenge{},
+		&entity.LoginAttempt{},
+		&entity.AccessToken{},
+		&entity.OidcState{},
+		&entity.OidcIdentity{},
+		&entity.AddressBook{},
+		&entity.AddressBookMember{},
+		&entity.AddressBookInvitation{},
 		&entity.Person{},
 		&entity.Tag{},
+		&entity.Relationship{},
+		&entity.Phone{},
+		&entity.ImportantDate{},
+		&entity.SmartList{},
 	); err != nil {
 		log.Fatalf("Failed to run database migrations: %v", err)
 		return err
 	}
 
+	if err := repository.MergeRelationshipJoinTables(db); err != nil {
+		log.Fatalf("Failed to merge relationship join tables: %v", err)
+		return err
+	}
+
+	if err := repository.MigrateSessionExpiryToMillis(db); err != nil {
+		log.Fatalf("Failed to migrate session expiry: %v", err)
+		return err
+	}
+
+	if err := repository.MigrateVerifiedAtToMillis(db); err != nil {
+		log.Fatalf("Failed to migrate verified at: %v", err)
+		return err
+	}
+
+	if err := repository.AssignPersonalAddressBooks(db); err != nil {
+		log.Fatalf("Failed to assign personal address books: %v", err)
+		return err
+	}
+
+	if err := repository.CreateSearchVectors(db); err != nil {
+		log.Fatalf("Failed to create search vectors: %v", err)
+		return err
+	}
+
+	if err := repository.CreateTrigramIndexes(db); err != nil {
+		log.Fatalf("Failed to create trigram indexes: %v", err)
+		return err
+	}
+
 	log.Info("Database migrations completed successfully.")
 	return nil
 }
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"codename-rl/internal/pkg/oidc"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+func NewOidc(config *viper.Viper) *oidc.Registry {
+	var providerConfigs []oidc.ProviderConfig
+	if err := config.UnmarshalKey("oidc.providers", &providerConfigs); err != nil {
+		panic(fmt.Errorf("fatal error loading oidc.providers: %w", err))
+	}
+
+	registry, err := oidc.NewRegistry(providerConfigs, nil)
+	if err != nil {
+		panic(fmt.Errorf("fatal error loading oidc provider: %w", err))
+	}
+	return registry
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"codename-rl/internal/delivery/http/middleware"
+	"codename-rl/internal/delivery/http/response"
+	"codename-rl/internal/model"
+	"codename-rl/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AccessTokenHandler struct {
+	Log     *logrus.Logger
+	UseCase *usecase.AccessTokenUseCase
+}
+
+func NewAccessTokenHandler(useCase *usecase.AccessTokenUseCase, logger *logrus.Logger) *AccessTokenHandler {
+	return &AccessTokenHandler{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *AccessTokenHandler) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateAccessTokenRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
+	responseData, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to create access token")
+		resp := response.NewErrorResponse("Failed to create access token", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Access token created successfully", responseData)
+	return ctx.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (c *AccessTokenHandler) Get(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetAccessTokenRequest{
+		UserID: auth.ID,
+	}
+
+	responseData, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get access tokens")
+		resp := response.NewErrorResponse("Failed to get access tokens", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Access tokens fetched successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *AccessTokenHandler) Delete(ctx *fiber.Ctx) error {
+	request := new(model.DeleteAccessTokenRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
+	responseData, err := c.UseCase.Delete(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to revoke access token")
+		resp := response.NewErrorResponse("Failed to revoke access token", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Access token revoked successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
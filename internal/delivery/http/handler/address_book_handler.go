@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"codename-rl/internal/delivery/http/middleware"
+	"codename-rl/internal/delivery/http/response"
+	"codename-rl/internal/model"
+	"codename-rl/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AddressBookHandler struct {
+	Log     *logrus.Logger
+	UseCase *usecase.AddressBookUseCase
+}
+
+func NewAddressBookHandler(useCase *usecase.AddressBookUseCase, logger *logrus.Logger) *AddressBookHandler {
+	return &AddressBookHandler{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *AddressBookHandler) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateAddressBookRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
+	responseData, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to create address book")
+		resp := response.NewErrorResponse("Failed to create address book", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book created successfully", responseData)
+	return ctx.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (c *AddressBookHandler) Get(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetAddressBookRequest{
+		UserID: auth.ID,
+	}
+
+	responseData, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get address books")
+		resp := response.NewErrorResponse("Failed to get address books", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address books fetched successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *AddressBookHandler) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateAddressBookRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+	request.ID = ctx.Params("id")
+
+	responseData, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to update address book")
+		resp := response.NewErrorResponse("Failed to update address book", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book updated successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *AddressBookHandler) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteAddressBookRequest{
+		ID:     ctx.Params("id"),
+		UserID: auth.ID,
+	}
+
+	responseData, err := c.UseCase.Delete(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to delete address book")
+		resp := response.NewErrorResponse("Failed to delete address book", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book deleted successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *AddressBookHandler) GetMembers(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetAddressBookMembersRequest{
+		AddressBookID: ctx.Params("id"),
+		UserID:        auth.ID,
+	}
+
+	responseData, err := c.UseCase.GetMembers(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get address book members")
+		resp := response.NewErrorResponse("Failed to get address book members", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book members fetched successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *AddressBookHandler) UpdateMember(ctx *fiber.Ctx) error {
+	request := new(model.UpdateAddressBookMemberRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+	request.AddressBookID = ctx.Params("id")
+
+	responseData, err := c.UseCase.UpdateMember(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to update address book member")
+		resp := response.NewErrorResponse("Failed to update address book member", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book member updated successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *AddressBookHandler) DeleteMember(ctx *fiber.Ctx) error {
+	request := new(model.DeleteAddressBookMemberRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+	request.AddressBookID = ctx.Params("id")
+
+	responseData, err := c.UseCase.DeleteMember(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to remove address book member")
+		resp := response.NewErrorResponse("Failed to remove address book member", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book member removed successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *AddressBookHandler) Invite(ctx *fiber.Ctx) error {
+	request := new(model.CreateAddressBookInvitationRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+	request.AddressBookID = ctx.Params("id")
+
+	responseData, err := c.UseCase.Invite(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to send address book invitation")
+		resp := response.NewErrorResponse("Failed to send address book invitation", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book invitation sent successfully", responseData)
+	return ctx.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (c *AddressBookHandler) GetInvitations(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetAddressBookInvitationRequest{
+		UserID: auth.ID,
+	}
+
+	responseData, err := c.UseCase.GetInvitations(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get address book invitations")
+		resp := response.NewErrorResponse("Failed to get address book invitations", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book invitations fetched successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *AddressBookHandler) AcceptInvitation(ctx *fiber.Ctx) error {
+	request := new(model.RespondAddressBookInvitationRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
+	responseData, err := c.UseCase.Accept(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to accept address book invitation")
+		resp := response.NewErrorResponse("Failed to accept address book invitation", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book invitation accepted successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *AddressBookHandler) DeclineInvitation(ctx *fiber.Ctx) error {
+	request := new(model.RespondAddressBookInvitationRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
+	responseData, err := c.UseCase.Decline(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to decline address book invitation")
+		resp := response.NewErrorResponse("Failed to decline address book invitation", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Address book invitation declined successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
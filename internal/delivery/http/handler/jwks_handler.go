@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"codename-rl/internal/pkg/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type JwksHandler struct {
+	Log        *logrus.Logger
+	JWTService *auth.JwtService
+}
+
+func NewJwksHandler(jwtService *auth.JwtService, logger *logrus.Logger) *JwksHandler {
+	return &JwksHandler{
+		Log:        logger,
+		JWTService: jwtService,
+	}
+}
+
+// Get serves the public signing keys as a bare JWK set so that other
+// services can verify our tokens with standard libraries.
+func (c *JwksHandler) Get(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.Status(fiber.StatusOK).JSON(c.JWTService.JWKS())
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"codename-rl/internal/model"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseListRequest reads the list options of a GET request from its query
+// string, e.g. ?filter[first_name][contains]=an&sort=-created_at&limit=20&include=tags.
+// Clients that still send a JSON body without any query parameters get it
+// parsed into request as before.
+func parseListRequest(ctx *fiber.Ctx, request any, q *model.Query) error {
+	params := listParams(ctx)
+	if len(params) == 0 {
+		if len(ctx.Body()) == 0 {
+			return nil
+		}
+		return ctx.BodyParser(request)
+	}
+	return parseListQuery(params, q)
+}
+
+// listParams returns the query string of ctx as key and value pairs, in order
+func listParams(ctx *fiber.Ctx) [][2]string {
+	var params [][2]string
+	ctx.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		params = append(params, [2]string{string(key), string(value)})
+	})
+	return params
+}
+
+// parseListQuery understands
+//
+//	filter[field][op]=value     a condition, all conditions must match
+//	filter[field]=value         shorthand for filter[field][eq]
+//	filter={"or":[...]}         a JSON filter for nested groups
+//	relations=[{...}]           JSON relation filters
+//	search[field]=value         the legacy search map
+//	search_mode=fuzzy           match search fields by similarity
+//	date_range[field][from|to]  a date range
+//	sort=last_name,-created_at  sort keys in order, descending with a minus
+//	sort=last_name:nulls_first  nulls and empty text first, or nulls_last
+//	collation=de-DE-x-icu       collation for text sort keys
+//	limit, offset, cursor, skip_total
+//	include=tags,relationships.persons  relations to preload, nested with dots
+//	fields=first_name,avatar    columns to return, all when left out
+//	fields[relationships]=name  columns to return for an included relation
+func parseListQuery(params [][2]string, q *model.Query) error {
+	var conditions []model.Filter
+
+	for _, param := range params {
+		key, value := param[0], param[1]
+		name, parts := splitBrackets(key)
+
+		switch {
+		case name == "filter" && len(parts) == 0:
+			filter := new(model.Filter)
+			if err := json.Unmarshal([]byte(value), filter); err != nil {
+				return badListQuery("filter is not valid JSON: %v", err)
+			}
+			conditions = append([]model.Filter{*filter}, conditions...)
+		case name == "filter" && (len(parts) == 1 || len(parts) == 2):
+			op := model.FilterEq
+			if len(parts) == 2 {
+				op = model.FilterOperator(parts[1])
+			}
+			conditions = append(conditions, model.Filter{Field: parts[0], Op: op, Value: filterValue(op, value)})
+		case key == "relations":
+			var relations []model.RelationFilter
+			if err := json.Unmarshal([]byte(value), &relations); err != nil {
+				return badListQuery("relations is not valid JSON: %v", err)
+			}
+			q.Relations = append(q.Relations, relations...)
+		case name == "search" && len(parts) == 1:
+			if q.Search == nil {
+				q.Search = map[string]string{}
+			}
+			q.Search[parts[0]] = value
+		case name == "date_range" && len(parts) == 2 && (parts[1] == "from" || parts[1] == "to"):
+			if q.DateRanges == nil {
+				q.DateRanges = map[string]model.DateRange{}
+			}
+			dr := q.DateRanges[parts[0]]
+			if parts[1] == "from" {
+				dr.From = value
+			} else {
+				dr.To = value
+			}
+			q.DateRanges[parts[0]] = dr
+		case key == "sort":
+			q.Sort = q.Sort[:0]
+			for _, term := range splitList(value) {
+				sortKey, err := parseSortKey(term)
+				if err != nil {
+					return err
+				}
+				q.Sort = append(q.Sort, sortKey)
+			}
+		case key == "collation":
+			q.Collation = value
+		case key == "limit" || key == "offset":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return badListQuery("%s must be a non-negative number", key)
+			}
+			if key == "limit" {
+				q.Limit = n
+			} else {
+				q.Offset = n
+			}
+		case key == "search_mode":
+			q.SearchMode = model.SearchMode(value)
+		case key == "cursor":
+			q.Cursor = value
+		case key == "skip_total":
+			skip, err := strconv.ParseBool(value)
+			if err != nil {
+				return badListQuery("skip_total must be true or false")
+			}
+			q.SkipTotal = skip
+		case key == "fields":
+			q.Fields = splitList(value)
+		case name == "fields" && len(parts) == 1:
+			if q.IncludeFields == nil {
+				q.IncludeFields = map[string][]string{}
+			}
+			q.IncludeFields[relationName(parts[0])] = splitList(value)
+		case key == "include":
+			for _, relation := range splitList(value) {
+				q.Preload = append(q.Preload, relationName(relation))
+			}
+		default:
+			return badListQuery("unknown query parameter %q", key)
+		}
+	}
+
+	switch len(conditions) {
+	case 0:
+	case 1:
+		q.Filter = &conditions[0]
+	default:
+		q.Filter = &model.Filter{And: conditions}
+	}
+	return nil
+}
+
+func badListQuery(format string, args ...any) error {
+	return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf(format, args...))
+}
+
+// splitBrackets splits "filter[first_name][contains]" into "filter" and
+// ["first_name", "contains"].
+func splitBrackets(key string) (string, []string) {
+	i := strings.IndexByte(key, '[')
+	if i < 0 || !strings.HasSuffix(key, "]") {
+		return key, nil
+	}
+	return key[:i], strings.Split(key[i+1:len(key)-1], "][")
+}
+
+// filterValue types a query string value the way a JSON filter would carry it
+func filterValue(op model.FilterOperator, value string) any {
+	switch op {
+	case model.FilterIn, model.FilterNotIn:
+		var list []any
+		for _, item := range strings.Split(value, ",") {
+			list = append(list, item)
+		}
+		return list
+	case model.FilterIsNull:
+		if isNull, err := strconv.ParseBool(value); err == nil {
+			return isNull
+		}
+	}
+	return value
+}
+
+// splitList splits a comma separated value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// relationName turns an include path like "relationships.important_dates"
+// into the relation path "Relationships.ImportantDates".
+func relationName(include string) string {
+	segments := strings.Split(include, ".")
+	for i, segment := range segments {
+		parts := strings.Split(segment, "_")
+		for j, part := range parts {
+			if part != "" {
+				parts[j] = strings.ToUpper(part[:1]) + part[1:]
+			}
+		}
+		segments[i] = strings.Join(parts, "")
+	}
+	return strings.Join(segments, ".")
+}
+
+// includeName is the inverse of relationName, it gives the JSON path of a
+// relation path.
+func includeName(relation string) string {
+	var b strings.Builder
+	for i, r := range relation {
+		if unicode.IsUpper(r) {
+			if i > 0 && relation[i-1] != '.' {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// parseSortKey reads one term of sort, e.g. -last_name:nulls_first
+func parseSortKey(term string) (model.SortKey, error) {
+	key := model.SortKey{Order: "asc"}
+	if strings.HasPrefix(term, "-") {
+		key.Order = "desc"
+		term = term[1:]
+	}
+	field, nulls, found := strings.Cut(term, ":")
+	key.Field = field
+	if found {
+		switch nulls {
+		case "nulls_first":
+			key.Nulls = "first"
+		case "nulls_last":
+			key.Nulls = "last"
+		default:
+			return key, badListQuery("sort on %s takes nulls_first or nulls_last, not %q", field, nulls)
+		}
+	}
+	if key.Field == "" {
+		return key, badListQuery("sort has an empty field")
+	}
+	return key, nil
+}
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"codename-rl/internal/model"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		params [][2]string
+		want   model.Query
+	}{
+		{
+			name:   "nothing",
+			params: nil,
+			want:   model.Query{},
+		},
+		{
+			name:   "filter shorthand is eq",
+			params: [][2]string{{"filter[first_name]", "Ann"}},
+			want:   model.Query{Filter: &model.Filter{Field: "first_name", Op: model.FilterEq, Value: "Ann"}},
+		},
+		{
+			name:   "filter with an operator",
+			params: [][2]string{{"filter[last_name][contains]", "ee"}},
+			want:   model.Query{Filter: &model.Filter{Field: "last_name", Op: model.FilterContains, Value: "ee"}},
+		},
+		{
+			name:   "in splits on commas",
+			params: [][2]string{{"filter[nickname][in]", "a,b,,c"}},
+			want:   model.Query{Filter: &model.Filter{Field: "nickname", Op: model.FilterIn, Value: []any{"a", "b", "", "c"}}},
+		},
+		{
+			name:   "is_null takes a boolean",
+			params: [][2]string{{"filter[nickname][is_null]", "true"}},
+			want:   model.Query{Filter: &model.Filter{Field: "nickname", Op: model.FilterIsNull, Value: true}},
+		},
+		{
+			name:   "is_null keeps a non boolean for the builder to reject",
+			params: [][2]string{{"filter[nickname][is_null]", "maybe"}},
+			want:   model.Query{Filter: &model.Filter{Field: "nickname", Op: model.FilterIsNull, Value: "maybe"}},
+		},
+		{
+			name: "conditions are joined with and, a JSON filter first",
+			params: [][2]string{
+				{"filter[first_name]", "Ann"},
+				{"filter", `{"or":[{"field":"last_name","op":"eq","value":"Lee"}]}`},
+			},
+			want: model.Query{Filter: &model.Filter{And: []model.Filter{
+				{Or: []model.Filter{{Field: "last_name", Op: model.FilterEq, Value: "Lee"}}},
+				{Field: "first_name", Op: model.FilterEq, Value: "Ann"},
+			}}},
+		},
+		{
+			name:   "sort keys in order with nulls",
+			params: [][2]string{{"sort", "last_name:nulls_first, -created_at,,-first_name:nulls_last"}},
+			want: model.Query{Sort: []model.SortKey{
+				{Field: "last_name", Order: "asc", Nulls: "first"},
+				{Field: "created_at", Order: "desc"},
+				{Field: "first_name", Order: "desc", Nulls: "last"},
+			}},
+		},
+		{
+			name:   "the last sort wins",
+			params: [][2]string{{"sort", "first_name"}, {"sort", "-last_name"}},
+			want:   model.Query{Sort: []model.SortKey{{Field: "last_name", Order: "desc"}}},
+		},
+		{
+			name: "paging",
+			params: [][2]string{
+				{"limit", "20"}, {"offset", "0"}, {"cursor", "abc"}, {"skip_total", "1"}, {"collation", "C"},
+			},
+			want: model.Query{Limit: 20, Cursor: "abc", SkipTotal: true, Collation: "C"},
+		},
+		{
+			name: "includes and fields",
+			params: [][2]string{
+				{"include", "tags,relationships.important_dates"},
+				{"fields", "first_name, avatar"},
+				{"fields[relationships.important_dates]", "date"},
+			},
+			want: model.Query{
+				Preload:       []string{"Tags", "Relationships.ImportantDates"},
+				Fields:        []string{"first_name", "avatar"},
+				IncludeFields: map[string][]string{"Relationships.ImportantDates": {"date"}},
+			},
+		},
+		{
+			name: "search and date ranges",
+			params: [][2]string{
+				{"search[first_name]", "an"},
+				{"search_mode", "fuzzy"},
+				{"date_range[created_at][from]", "2025-01-01"},
+				{"date_range[created_at][to]", "2025-02-01"},
+			},
+			want: model.Query{
+				Search:     map[string]string{"first_name": "an"},
+				SearchMode: model.SearchMode("fuzzy"),
+				DateRanges: map[string]model.DateRange{"created_at": {From: "2025-01-01", To: "2025-02-01"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q model.Query
+			if err := parseListQuery(tt.params, &q); err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(q, tt.want) {
+				t.Fatalf("query = %+v\nwant  %+v", q, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseListQueryRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		params [][2]string
+		reason string
+	}{
+		{name: "unknown parameter", params: [][2]string{{"page", "2"}}, reason: "unknown query parameter"},
+		{name: "unclosed bracket", params: [][2]string{{"filter[first_name", "Ann"}}, reason: "unknown query parameter"},
+		{name: "unopened bracket", params: [][2]string{{"filterfirst_name]", "Ann"}}, reason: "unknown query parameter"},
+		{name: "too many brackets", params: [][2]string{{"filter[first_name][eq][x]", "Ann"}}, reason: "unknown query parameter"},
+		{name: "text after the brackets", params: [][2]string{{"filter[first_name]x", "Ann"}}, reason: "unknown query parameter"},
+		{name: "search without a field", params: [][2]string{{"search", "Ann"}}, reason: "unknown query parameter"},
+		{name: "date range without a bound", params: [][2]string{{"date_range[created_at]", "2025-01-01"}}, reason: "unknown query parameter"},
+		{name: "date range with an unknown bound", params: [][2]string{{"date_range[created_at][until]", "2025-01-01"}}, reason: "unknown query parameter"},
+		{name: "fields nested too deep", params: [][2]string{{"fields[tags][name]", "x"}}, reason: "unknown query parameter"},
+		{name: "filter is not JSON", params: [][2]string{{"filter", "{"}}, reason: "filter is not valid JSON"},
+		{name: "relations are not JSON", params: [][2]string{{"relations", "[{"}}, reason: "relations is not valid JSON"},
+		{name: "negative limit", params: [][2]string{{"limit", "-1"}}, reason: "limit must be a non-negative number"},
+		{name: "limit is not a number", params: [][2]string{{"limit", "ten"}}, reason: "limit must be a non-negative number"},
+		{name: "offset is not a number", params: [][2]string{{"offset", "1.5"}}, reason: "offset must be a non-negative number"},
+		{name: "skip_total is not a boolean", params: [][2]string{{"skip_total", "yes"}}, reason: "skip_total must be true or false"},
+		{name: "sort with unknown nulls", params: [][2]string{{"sort", "first_name:nulls_middle"}}, reason: "takes nulls_first or nulls_last"},
+		{name: "sort with an empty field", params: [][2]string{{"sort", "-"}}, reason: "sort has an empty field"},
+		{name: "sort with only nulls", params: [][2]string{{"sort", ":nulls_first"}}, reason: "sort has an empty field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q model.Query
+			err := parseListQuery(tt.params, &q)
+			var fiberErr *fiber.Error
+			if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest {
+				t.Fatalf("err = %v, want a 400", err)
+			}
+			if !strings.Contains(fiberErr.Message, tt.reason) {
+				t.Fatalf("message = %q, want it to contain %q", fiberErr.Message, tt.reason)
+			}
+		})
+	}
+}
+
+func TestSplitBrackets(t *testing.T) {
+	tests := []struct {
+		key   string
+		name  string
+		parts []string
+	}{
+		{key: "limit", name: "limit"},
+		{key: "filter[first_name]", name: "filter", parts: []string{"first_name"}},
+		{key: "filter[first_name][contains]", name: "filter", parts: []string{"first_name", "contains"}},
+		{key: "filter[]", name: "filter", parts: []string{""}},
+		{key: "filter[first_name", name: "filter[first_name"},
+		{key: "filter]", name: "filter]"},
+		{key: "filter[a]b]", name: "filter", parts: []string{"a]b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			name, parts := splitBrackets(tt.key)
+			if name != tt.name || !reflect.DeepEqual(parts, tt.parts) {
+				t.Fatalf("splitBrackets = %q %q, want %q %q", name, parts, tt.name, tt.parts)
+			}
+		})
+	}
+}
+
+func TestRelationName(t *testing.T) {
+	tests := []struct {
+		include  string
+		relation string
+	}{
+		{include: "tags", relation: "Tags"},
+		{include: "important_dates", relation: "ImportantDates"},
+		{include: "relationships.important_dates", relation: "Relationships.ImportantDates"},
+		{include: "relationships.persons.tags", relation: "Relationships.Persons.Tags"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.include, func(t *testing.T) {
+			if got := relationName(tt.include); got != tt.relation {
+				t.Fatalf("relationName = %q, want %q", got, tt.relation)
+			}
+			if got := includeName(tt.relation); got != tt.include {
+				t.Fatalf("includeName = %q, want %q", got, tt.include)
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "", want: nil},
+		{value: " , ,", want: nil},
+		{value: "a", want: []string{"a"}},
+		{value: " a ,b,,c ", want: []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := splitList(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitList = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
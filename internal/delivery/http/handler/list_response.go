@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"codename-rl/internal/delivery/http/response"
+	"codename-rl/internal/model"
+	"encoding/json"
+	"strings"
+)
+
+// listResponse builds the paginated response of a list request. With sparse
+// fieldsets in q, every row and included relation is trimmed to the fields
+// asked for, so unselected columns do not show up as zero values.
+func listResponse[T any](message string, data []T, q *model.Query, info *model.PageInfo) (any, error) {
+	pageSize := q.Limit
+	if pageSize == 0 {
+		pageSize = 10
+	}
+	// A cursor page has no number: rows may have come or gone before it
+	var page *int
+	if q.Cursor == "" {
+		number := (q.Offset / pageSize) + 1
+		page = &number
+	}
+
+	if len(q.Fields) == 0 && len(q.IncludeFields) == 0 {
+		return response.NewPaginatedResponse(message, data, info, page, pageSize), nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var rows []any
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, err
+	}
+
+	projection := newProjection(q)
+	for i := range rows {
+		rows[i] = projection.apply(rows[i])
+	}
+	return response.NewPaginatedResponse(message, rows, info, page, pageSize), nil
+}
+
+// projection is the JSON shape a sparse list keeps: its fields, all when nil,
+// and the projection of each included relation.
+type projection struct {
+	fields   map[string]bool
+	includes map[string]*projection
+}
+
+func newProjection(q *model.Query) *projection {
+	root := &projection{fields: fieldSet(q.Fields)}
+	for _, path := range q.Preload {
+		root.at(includeName(path))
+	}
+	for path, fields := range q.IncludeFields {
+		root.at(includeName(path)).fields = fieldSet(fields)
+	}
+	return root
+}
+
+// at returns the projection of a dotted include path, creating it as needed
+func (p *projection) at(path string) *projection {
+	node := p
+	for _, name := range strings.Split(path, ".") {
+		if node.includes == nil {
+			node.includes = map[string]*projection{}
+		}
+		child, ok := node.includes[name]
+		if !ok {
+			child = &projection{}
+			node.includes[name] = child
+		}
+		node = child
+	}
+	return node
+}
+
+func (p *projection) apply(value any) any {
+	switch v := value.(type) {
+	case []any:
+		for i := range v {
+			v[i] = p.apply(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			if p.fields != nil && !p.fields[key] && p.includes[key] == nil && key != "id" {
+				delete(v, key)
+			}
+		}
+		for key, include := range p.includes {
+			if child, ok := v[key]; ok {
+				v[key] = include.apply(child)
+			}
+		}
+	}
+	return value
+}
+
+func fieldSet(fields []string) map[string]bool {
+	if len(fields) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		set[field] = true
+	}
+	return set
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"codename-rl/internal/delivery/http/response"
+	"codename-rl/internal/model"
+	"codename-rl/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type OidcHandler struct {
+	Log     *logrus.Logger
+	UseCase *usecase.OidcUseCase
+}
+
+func NewOidcHandler(useCase *usecase.OidcUseCase, logger *logrus.Logger) *OidcHandler {
+	return &OidcHandler{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *OidcHandler) Authorize(ctx *fiber.Ctx) error {
+	request := &model.OidcAuthorizeRequest{
+		Provider: ctx.Params("provider"),
+	}
+
+	responseData, err := c.UseCase.Authorize(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to start OIDC login : %+v", err)
+		resp := response.NewErrorResponse("Failed to start OIDC login", err)
+		return ctx.Status(errorStatus(err)).JSON(resp)
+	}
+
+	resp := response.NewResponse("OIDC login started", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *OidcHandler) Callback(ctx *fiber.Ctx) error {
+	request := new(model.OidcCallbackRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	request.Provider = ctx.Params("provider")
+
+	responseData, err := c.UseCase.Callback(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to complete OIDC login : %+v", err)
+		resp := response.NewErrorResponse("Failed to complete OIDC login", err)
+		return ctx.Status(errorStatus(err)).JSON(resp)
+	}
+
+	resp := response.NewResponse("Login successful", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"codename-rl/internal/delivery/http/middleware"
 	"codename-rl/internal/delivery/http/response"
 	"codename-rl/internal/model"
 	"codename-rl/internal/usecase"
@@ -42,19 +43,40 @@ func (c *OtpController) CreateOtp(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(resp)
 }
 
+func (c *OtpController) ResendVerification(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ResendVerificationRequest{
+		UserID: auth.ID,
+	}
+
+	responseData, err := c.UseCase.ResendVerification(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to resend verification OTP : %+v", err)
+		resp := response.NewErrorResponse("Failed to resend verification OTP", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(resp)
+	}
+
+	resp := response.NewResponse("OTP Created Successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
 func (c *OtpController) VerifyOtpUser(ctx *fiber.Ctx) error {
-	request := new(model.VerifyOtpRequest)
+	request := new(model.VerifyEmailRequest)
 	if err := ctx.BodyParser(request); err != nil {
 		c.Log.Warnf("Failed to parse request body : %+v", err)
 		resp := response.NewErrorResponse("Invalid request body", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
 	}
 
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
 	responseData, err := c.UseCase.VerifyUser(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.Warnf("Failed to verify OTP : %+v", err)
 		resp := response.NewErrorResponse("Failed to verify OTP", err)
-		return ctx.Status(fiber.StatusUnauthorized).JSON(resp)
+		return ctx.Status(loginErrorStatus(ctx, err)).JSON(resp)
 	}
 
 	resp := response.NewResponse("OTP verification successful", responseData)
@@ -69,11 +91,11 @@ func (c *OtpController) VerifyOtpForgotPassword(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
 	}
 
-	responseData, err := c.UseCase.VerifyUser(ctx.UserContext(), request)
+	responseData, err := c.UseCase.VerifyForgotPassword(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.Warnf("Failed to verify OTP : %+v", err)
 		resp := response.NewErrorResponse("Failed to verify OTP", err)
-		return ctx.Status(fiber.StatusUnauthorized).JSON(resp)
+		return ctx.Status(loginErrorStatus(ctx, err)).JSON(resp)
 	}
 
 	resp := response.NewResponse("OTP verification successful", responseData)
@@ -5,11 +5,33 @@ import (
 	"codename-rl/internal/delivery/http/response"
 	"codename-rl/internal/model"
 	"codename-rl/internal/usecase"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
 
+// personRelationScopes is the read scope each person relation needs on top
+// of persons:read, keyed by its name in relation filters
+var personRelationScopes = []struct {
+	relation string
+	scope    string
+}{
+	{"phones", "phones:read"},
+	{"important_dates", "dates:read"},
+}
+
+// omittedPersonRelations lists the person relations auth's scopes cannot read
+func omittedPersonRelations(auth *model.Auth) []string {
+	var omit []string
+	for _, s := range personRelationScopes {
+		if !auth.HasScope(s.scope) {
+			omit = append(omit, s.relation)
+		}
+	}
+	return omit
+}
+
 type PersonHandler struct {
 	Log     *logrus.Logger
 	UseCase *usecase.PersonUseCase
@@ -48,29 +70,109 @@ func (c *PersonHandler) Create(ctx *fiber.Ctx) error {
 func (c *PersonHandler) Get(ctx *fiber.Ctx) error {
 	request := new(model.GetPersonRequest)
 
-	if err := ctx.BodyParser(request); err != nil {
-		c.Log.Warnf("Failed to parse request body : %+v", err)
-		resp := response.NewErrorResponse("Invalid request body", err)
+	if err := parseListRequest(ctx, request, &request.Query); err != nil {
+		c.Log.Warnf("Failed to parse list query : %+v", err)
+		resp := response.NewErrorResponse("Invalid list query", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
 	}
 
 	auth := middleware.GetUser(ctx)
 	request.UserID = auth.ID
+	request.Omit = omittedPersonRelations(auth)
 
-	responseData, total, err := c.UseCase.Get(ctx.UserContext(), request)
+	responseData, pageInfo, err := c.UseCase.Get(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.WithError(err).Warnf("Failed to get persons")
 		resp := response.NewErrorResponse("Failed to get persons", err)
 		return ctx.Status(err.Code).JSON(resp)
 	}
 
-	pageSize := request.Query.Limit
-	if pageSize == 0 {
-		pageSize = 10
+	resp, buildErr := listResponse("Get persons fetched successfully", *responseData, &request.Query, pageInfo)
+	if buildErr != nil {
+		c.Log.Warnf("Failed to build list response : %+v", buildErr)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.NewErrorResponse("Failed to build response", buildErr))
 	}
-	page := (request.Query.Offset / pageSize) + 1
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
 
-	resp := response.NewPaginatedResponse("Get persons fetched successfully", *responseData, total, page, pageSize)
+func (c *PersonHandler) GetByID(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetPersonByIDRequest{
+		ID:     ctx.Params("id"),
+		UserID: auth.ID,
+		Omit:   omittedPersonRelations(auth),
+	}
+
+	responseData, err := c.UseCase.GetByID(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get person")
+		resp := response.NewErrorResponse("Failed to get person", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Person fetched successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *PersonHandler) GetSimilar(ctx *fiber.Ctx) error {
+	request := new(model.GetSimilarPersonRequest)
+
+	if err := ctx.QueryParser(request); err != nil {
+		c.Log.Warnf("Failed to parse query : %+v", err)
+		resp := response.NewErrorResponse("Invalid query", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
+	responseData, err := c.UseCase.GetSimilar(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get similar persons")
+		resp := response.NewErrorResponse("Failed to get similar persons", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Similar persons fetched successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *PersonHandler) GetFacets(ctx *fiber.Ctx) error {
+	request := new(model.GetPersonFacetsRequest)
+
+	var params [][2]string
+	for _, param := range listParams(ctx) {
+		if param[0] != "upcoming_days" {
+			params = append(params, param)
+			continue
+		}
+		days, err := strconv.Atoi(param[1])
+		if err != nil {
+			c.Log.Warnf("Failed to parse list query : %+v", err)
+			resp := response.NewErrorResponse("Invalid list query", badListQuery("upcoming_days must be a number"))
+			return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+		}
+		request.UpcomingDays = days
+	}
+	if err := parseListQuery(params, &request.Query); err != nil {
+		c.Log.Warnf("Failed to parse list query : %+v", err)
+		resp := response.NewErrorResponse("Invalid list query", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+	request.Omit = omittedPersonRelations(auth)
+
+	responseData, err := c.UseCase.GetFacets(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get person facets")
+		resp := response.NewErrorResponse("Failed to get person facets", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Person facets fetched successfully", responseData)
 	return ctx.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -102,6 +204,9 @@ func (c *PersonHandler) Delete(ctx *fiber.Ctx) error {
 
 	request.ID = ctx.Params("id")
 
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
 	responseData, err := c.UseCase.Delete(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.WithError(err).Warnf("Failed to delete person")
@@ -48,29 +48,27 @@ func (c *PhoneHandler) Create(ctx *fiber.Ctx) error {
 func (c *PhoneHandler) Get(ctx *fiber.Ctx) error {
 	request := new(model.GetPhoneRequest)
 
-	if err := ctx.BodyParser(request); err != nil {
-		c.Log.Warnf("Failed to parse request body : %+v", err)
-		resp := response.NewErrorResponse("Invalid request body", err)
+	if err := parseListRequest(ctx, request, &request.Query); err != nil {
+		c.Log.Warnf("Failed to parse list query : %+v", err)
+		resp := response.NewErrorResponse("Invalid list query", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
 	}
 
 	auth := middleware.GetUser(ctx)
 	request.UserID = auth.ID
 
-	responseData, total, err := c.UseCase.Get(ctx.UserContext(), request)
+	responseData, pageInfo, err := c.UseCase.Get(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.WithError(err).Warnf("Failed to get phones")
 		resp := response.NewErrorResponse("Failed to get phones", err)
 		return ctx.Status(err.Code).JSON(resp)
 	}
 
-	pageSize := request.Query.Limit
-	if pageSize == 0 {
-		pageSize = 10
+	resp, buildErr := listResponse("Get phones fetched successfully", *responseData, &request.Query, pageInfo)
+	if buildErr != nil {
+		c.Log.Warnf("Failed to build list response : %+v", buildErr)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.NewErrorResponse("Failed to build response", buildErr))
 	}
-	page := (request.Query.Offset / pageSize) + 1
-
-	resp := response.NewPaginatedResponse("Get phones fetched successfully", *responseData, total, page, pageSize)
 	return ctx.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"codename-rl/internal/delivery/http/middleware"
+	"codename-rl/internal/delivery/http/response"
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"codename-rl/internal/usecase"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// searchHitScopes is the read scope each search hit type needs
+var searchHitScopes = []struct {
+	hitType string
+	scope   string
+}{
+	{entity.SearchHitPerson, "persons:read"},
+	{entity.SearchHitPhone, "phones:read"},
+	{entity.SearchHitTag, "tags:read"},
+	{entity.SearchHitImportantDate, "dates:read"},
+}
+
+type SearchHandler struct {
+	Log     *logrus.Logger
+	UseCase *usecase.SearchUseCase
+}
+
+func NewSearchHandler(useCase *usecase.SearchUseCase, logger *logrus.Logger) *SearchHandler {
+	return &SearchHandler{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *SearchHandler) Search(ctx *fiber.Ctx) error {
+	request := new(model.SearchRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		c.Log.Warnf("Failed to parse query : %+v", err)
+		resp := response.NewErrorResponse("Invalid search query", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
+	// Personal access tokens only search what their scopes can read. The
+	// types are set here alone, never taken from the query string.
+	var allowed []string
+	for _, s := range searchHitScopes {
+		if auth.HasScope(s.scope) {
+			allowed = append(allowed, s.hitType)
+		}
+	}
+	request.Types = allowed
+	if len(request.Types) == 0 {
+		resp := response.NewErrorResponse("Insufficient scope", errors.New("token cannot read any searchable resource"))
+		return ctx.Status(fiber.StatusForbidden).JSON(resp)
+	}
+
+	responseData, err := c.UseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to search")
+		resp := response.NewErrorResponse("Failed to search", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Search completed successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"codename-rl/internal/delivery/http/middleware"
+	"codename-rl/internal/delivery/http/response"
+	"codename-rl/internal/model"
+	"codename-rl/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type SmartListHandler struct {
+	Log     *logrus.Logger
+	UseCase *usecase.SmartListUseCase
+}
+
+func NewSmartListHandler(useCase *usecase.SmartListUseCase, logger *logrus.Logger) *SmartListHandler {
+	return &SmartListHandler{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *SmartListHandler) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateSmartListRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+	request.Omit = omittedPersonRelations(auth)
+
+	responseData, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to create smart list")
+		resp := response.NewErrorResponse("Failed to create smart list", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Smart list created successfully", responseData)
+	return ctx.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (c *SmartListHandler) Get(ctx *fiber.Ctx) error {
+	request := new(model.GetSmartListRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		c.Log.Warnf("Failed to parse query : %+v", err)
+		resp := response.NewErrorResponse("Invalid query", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+
+	responseData, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get smart lists")
+		resp := response.NewErrorResponse("Failed to get smart lists", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Smart lists fetched successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *SmartListHandler) GetByID(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetSmartListByIDRequest{
+		ID:     ctx.Params("id"),
+		UserID: auth.ID,
+	}
+
+	responseData, err := c.UseCase.GetByID(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to get smart list")
+		resp := response.NewErrorResponse("Failed to get smart list", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Smart list fetched successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *SmartListHandler) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateSmartListRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+	request.Omit = omittedPersonRelations(auth)
+	request.ID = ctx.Params("id")
+
+	responseData, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to update smart list")
+		resp := response.NewErrorResponse("Failed to update smart list", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Smart list updated successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *SmartListHandler) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteSmartListRequest{
+		ID:     ctx.Params("id"),
+		UserID: auth.ID,
+	}
+
+	responseData, err := c.UseCase.Delete(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to delete smart list")
+		resp := response.NewErrorResponse("Failed to delete smart list", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Smart list deleted successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+// Evaluate returns a page of the persons a smart list matches right now
+func (c *SmartListHandler) Evaluate(ctx *fiber.Ctx) error {
+	request := new(model.EvaluateSmartListRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		c.Log.Warnf("Failed to parse query : %+v", err)
+		resp := response.NewErrorResponse("Invalid query", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.UserID = auth.ID
+	request.Omit = omittedPersonRelations(auth)
+	request.ID = ctx.Params("id")
+
+	responseData, query, pageInfo, err := c.UseCase.Evaluate(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to evaluate smart list")
+		resp := response.NewErrorResponse("Failed to evaluate smart list", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp, buildErr := listResponse("Smart list evaluated successfully", *responseData, query, pageInfo)
+	if buildErr != nil {
+		c.Log.Warnf("Failed to build list response : %+v", buildErr)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.NewErrorResponse("Failed to build response", buildErr))
+	}
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
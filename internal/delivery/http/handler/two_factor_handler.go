@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"codename-rl/internal/delivery/http/middleware"
+	"codename-rl/internal/delivery/http/response"
+	"codename-rl/internal/model"
+	"codename-rl/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type TwoFactorHandler struct {
+	Log     *logrus.Logger
+	UseCase *usecase.TwoFactorUseCase
+}
+
+func NewTwoFactorHandler(useCase *usecase.TwoFactorUseCase, logger *logrus.Logger) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *TwoFactorHandler) Enroll(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.EnrollTwoFactorRequest{
+		ID: auth.ID,
+	}
+
+	responseData, err := c.UseCase.Enroll(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to enroll two-factor authentication")
+		resp := response.NewErrorResponse("Failed to enroll two-factor authentication", err)
+		return ctx.Status(err.Code).JSON(resp)
+	}
+
+	resp := response.NewResponse("Two-factor enrolment started", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *TwoFactorHandler) Confirm(ctx *fiber.Ctx) error {
+	request := new(model.ConfirmTwoFactorRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.ID = auth.ID
+	request.IP = ctx.IP()
+
+	responseData, err := c.UseCase.Confirm(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to confirm two-factor authentication")
+		resp := response.NewErrorResponse("Failed to confirm two-factor authentication", err)
+		return ctx.Status(attemptErrorStatus(ctx, err)).JSON(resp)
+	}
+
+	resp := response.NewResponse("Two-factor authentication enabled", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *TwoFactorHandler) Disable(ctx *fiber.Ctx) error {
+	request := new(model.DisableTwoFactorRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.ID = auth.ID
+	request.IP = ctx.IP()
+
+	responseData, err := c.UseCase.Disable(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to disable two-factor authentication")
+		resp := response.NewErrorResponse("Failed to disable two-factor authentication", err)
+		return ctx.Status(attemptErrorStatus(ctx, err)).JSON(resp)
+	}
+
+	resp := response.NewResponse("Two-factor authentication disabled", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *TwoFactorHandler) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	request := new(model.RegenerateRecoveryCodesRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.ID = auth.ID
+	request.IP = ctx.IP()
+
+	responseData, err := c.UseCase.RegenerateRecoveryCodes(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to regenerate recovery codes")
+		resp := response.NewErrorResponse("Failed to regenerate recovery codes", err)
+		return ctx.Status(attemptErrorStatus(ctx, err)).JSON(resp)
+	}
+
+	resp := response.NewResponse("Recovery codes regenerated", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
@@ -5,6 +5,9 @@ import (
 	"codename-rl/internal/delivery/http/response"
 	"codename-rl/internal/model"
 	"codename-rl/internal/usecase"
+	"errors"
+	"math"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
@@ -51,17 +54,59 @@ func (c *UserController) Login(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
 	}
 
+	request.IP = ctx.IP()
+
 	responseData, err := c.UseCase.Login(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.Warnf("Failed to login user : %+v", err)
 		resp := response.NewErrorResponse("Failed to login user", err)
-		return ctx.Status(fiber.StatusUnauthorized).JSON(resp)
+		return ctx.Status(loginErrorStatus(ctx, err)).JSON(resp)
+	}
+
+	resp := response.NewResponse("Login successful", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (c *UserController) LoginTwoFactor(ctx *fiber.Ctx) error {
+	request := new(model.LoginTwoFactorRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	request.IP = ctx.IP()
+
+	responseData, err := c.UseCase.LoginTwoFactor(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to login user : %+v", err)
+		resp := response.NewErrorResponse("Failed to login user", err)
+		return ctx.Status(loginErrorStatus(ctx, err)).JSON(resp)
 	}
 
 	resp := response.NewResponse("Login successful", responseData)
 	return ctx.Status(fiber.StatusOK).JSON(resp)
 }
 
+func (c *UserController) Refresh(ctx *fiber.Ctx) error {
+	request := new(model.RefreshUserRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	responseData, err := c.UseCase.Refresh(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to refresh token : %+v", err)
+		resp := response.NewErrorResponse("Failed to refresh token", err)
+		return ctx.Status(errorStatus(err)).JSON(resp)
+	}
+
+	resp := response.NewResponse("Token refreshed successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
 func (c *UserController) Current(ctx *fiber.Ctx) error {
 	auth := middleware.GetUser(ctx)
 
@@ -84,7 +129,8 @@ func (c *UserController) Logout(ctx *fiber.Ctx) error {
 	auth := middleware.GetUser(ctx)
 
 	request := &model.LogoutUserRequest{
-		ID: auth.ID,
+		ID:        auth.ID,
+		SessionID: auth.SessionID,
 	}
 
 	responseData, err := c.UseCase.Logout(ctx.UserContext(), request)
@@ -113,13 +159,35 @@ func (c *UserController) Update(ctx *fiber.Ctx) error {
 	if err != nil {
 		c.Log.WithError(err).Warnf("Failed to update user")
 		resp := response.NewErrorResponse("Failed to update user", err)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(resp)
+		return ctx.Status(errorStatus(err)).JSON(resp)
 	}
 
 	resp := response.NewResponse("User updated successfully", responseData)
 	return ctx.Status(fiber.StatusOK).JSON(resp)
 }
 
+func (c *UserController) ConfirmEmailChange(ctx *fiber.Ctx) error {
+	request := new(model.ConfirmEmailChangeRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		resp := response.NewErrorResponse("Invalid request body", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(resp)
+	}
+
+	auth := middleware.GetUser(ctx)
+	request.ID = auth.ID
+
+	responseData, err := c.UseCase.ConfirmEmailChange(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to confirm email change")
+		resp := response.NewErrorResponse("Failed to confirm email change", err)
+		return ctx.Status(errorStatus(err)).JSON(resp)
+	}
+
+	resp := response.NewResponse("Email changed successfully", responseData)
+	return ctx.Status(fiber.StatusOK).JSON(resp)
+}
+
 func (c *UserController) UpdatePassword(ctx *fiber.Ctx) error {
 	request := new(model.UpdateUserPasswordRequest)
 	if err := ctx.BodyParser(request); err != nil {
@@ -138,3 +206,37 @@ func (c *UserController) UpdatePassword(ctx *fiber.Ctx) error {
 	resp := response.NewResponse("User updated successfully", responseData)
 	return ctx.Status(fiber.StatusOK).JSON(resp)
 }
+
+// errorStatus is the status of a usecase error, 500 unless it carries one
+func errorStatus(err error) int {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// attemptErrorStatus answers a lockout like loginErrorStatus does and any
+// other error with its own status.
+func attemptErrorStatus(ctx *fiber.Ctx, err error) int {
+	var tooMany *usecase.TooManyAttemptsError
+	if errors.As(err, &tooMany) {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+		return fiber.StatusTooManyRequests
+	}
+	return errorStatus(err)
+}
+
+// loginErrorStatus maps throttling errors to 429, setting Retry-After when the
+// lockout has a known end, and everything else to 401.
+func loginErrorStatus(ctx *fiber.Ctx, err error) int {
+	var tooMany *usecase.TooManyAttemptsError
+	if errors.As(err, &tooMany) {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+		return fiber.StatusTooManyRequests
+	}
+	if errors.Is(err, fiber.ErrTooManyRequests) {
+		return fiber.StatusTooManyRequests
+	}
+	return fiber.StatusUnauthorized
+}
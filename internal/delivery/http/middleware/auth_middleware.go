@@ -1,13 +1,36 @@
 package middleware
 
 import (
+	"codename-rl/internal/delivery/http/response"
 	"codename-rl/internal/model"
 	"codename-rl/internal/usecase"
+	"errors"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func NewAuth(userUserCase *usecase.UserUseCase) fiber.Handler {
+// VerificationPolicy limits accounts without a verified email address to
+// AllowedRoutes, given as "METHOD /path", until they confirm their email.
+type VerificationPolicy struct {
+	RequireVerifiedEmail bool
+	AllowedRoutes        []string
+}
+
+func (p *VerificationPolicy) allows(auth *model.Auth, ctx *fiber.Ctx) bool {
+	if p == nil || !p.RequireVerifiedEmail || auth.Verified {
+		return true
+	}
+
+	route := ctx.Method() + " " + ctx.Path()
+	for _, allowed := range p.AllowedRoutes {
+		if allowed == route {
+			return true
+		}
+	}
+	return false
+}
+
+func NewAuth(userUserCase *usecase.UserUseCase, policy *VerificationPolicy) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		request := &model.VerifyUserRequest{Token: ctx.Get("Authorization", "NOT_FOUND")}
 		userUserCase.Log.Debugf("Authorization : %s", request.Token)
@@ -18,6 +41,12 @@ func NewAuth(userUserCase *usecase.UserUseCase) fiber.Handler {
 			return fiber.ErrUnauthorized
 		}
 
+		if !policy.allows(auth, ctx) {
+			userUserCase.Log.Warnf("Unverified user %s blocked from %s %s", auth.ID, ctx.Method(), ctx.Path())
+			resp := response.NewErrorResponse("Email address not verified", errors.New("verify your email address to use this route"))
+			return ctx.Status(fiber.StatusForbidden).JSON(resp)
+		}
+
 		userUserCase.Log.Debugf("User : %+v", auth.ID)
 		ctx.Locals("auth", auth)
 		return ctx.Next()
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"codename-rl/internal/delivery/http/response"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope guards a resource route group. GET requests need
+// "<resource>:read", every other method "<resource>:write".
+func RequireScope(resource string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		scope := resource + ":write"
+		if ctx.Method() == fiber.MethodGet {
+			scope = resource + ":read"
+		}
+
+		if !GetUser(ctx).HasScope(scope) {
+			resp := response.NewErrorResponse("Insufficient scope", errors.New("token is missing scope "+scope))
+			return ctx.Status(fiber.StatusForbidden).JSON(resp)
+		}
+		return ctx.Next()
+	}
+}
+
+// RequireSession keeps personal access tokens away from account management routes
+func RequireSession() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if GetUser(ctx).AccessTokenID != "" {
+			resp := response.NewErrorResponse("Session required", errors.New("personal access tokens cannot manage the account"))
+			return ctx.Status(fiber.StatusForbidden).JSON(resp)
+		}
+		return ctx.Next()
+	}
+}
@@ -33,14 +33,18 @@ func WriteJSON[T any](w http.ResponseWriter, status int, resp *model.BaseRespons
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
-func NewPaginatedResponse[T any](message string, data []T, total int64, page int, pageSize int) *model.BaseResponse[[]T] {
+func NewPaginatedResponse[T any](message string, data []T, info *model.PageInfo, page *int, pageSize int) *model.BaseResponse[[]T] {
 
-	totalPages := int64((total + int64(pageSize) - 1) / int64(pageSize))
 	meta := model.PaginationMeta{
-		Total:      total,
+		Total:      info.Total,
 		Page:       page,
 		PageSize:   pageSize,
-		TotalPages: totalPages,
+		NextCursor: info.NextCursor,
+		PrevCursor: info.PrevCursor,
+	}
+	if info.Total != nil {
+		totalPages := int64((*info.Total + int64(pageSize) - 1) / int64(pageSize))
+		meta.TotalPages = &totalPages
 	}
 
 	return &model.BaseResponse[[]T]{
@@ -2,6 +2,7 @@ package route
 
 import (
 	"codename-rl/internal/delivery/http/handler"
+	"codename-rl/internal/delivery/http/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -10,11 +11,18 @@ type Config struct {
 	App                     *fiber.App
 	UserController          *handler.UserController
 	OtpController           *handler.OtpController
+	TwoFactorController     *handler.TwoFactorHandler
 	TagController           *handler.TagHandler
 	PersonController        *handler.PersonHandler
 	RelationshipController  *handler.RelationshipHandler
 	PhoneController         *handler.PhoneHandler
 	ImportantDateController *handler.ImportantDateHandler
+	AddressBookController   *handler.AddressBookHandler
+	SearchController        *handler.SearchHandler
+	SmartListController     *handler.SmartListHandler
+	JwksController          *handler.JwksHandler
+	AccessTokenController   *handler.AccessTokenHandler
+	OidcController          *handler.OidcHandler
 	AuthMiddleware          fiber.Handler
 }
 
@@ -24,9 +32,18 @@ func (c *Config) Setup() {
 }
 
 func (c *Config) SetupGuestRoute() {
+	// JWKS
+	c.App.Get("/.well-known/jwks.json", c.JwksController.Get)
+
 	// User
 	c.App.Post("/api/users", c.UserController.Register)
 	c.App.Post("/api/users/_login", c.UserController.Login)
+	c.App.Post("/api/users/_refresh", c.UserController.Refresh)
+	c.App.Post("/api/users/_login/2fa", c.UserController.LoginTwoFactor)
+
+	// OIDC
+	c.App.Get("/api/users/_oidc/:provider", c.OidcController.Authorize)
+	c.App.Post("/api/users/_oidc/:provider/callback", c.OidcController.Callback)
 
 	// OTP
 	c.App.Post("/api/users/_otp", c.OtpController.CreateOtp)
@@ -37,40 +54,90 @@ func (c *Config) SetupGuestRoute() {
 func (c *Config) SetupAuthRoute() {
 	// Middleware
 	c.App.Use(c.AuthMiddleware)
-	c.App.Delete("/api/users", c.UserController.Logout)
-	c.App.Patch("/api/users/_current", c.UserController.Update)
-	c.App.Get("/api/users/_current", c.UserController.Current)
+
+	// User account routes only accept session tokens
+	users := c.App.Group("/api/users", middleware.RequireSession())
+	users.Delete("", c.UserController.Logout)
+	users.Patch("/_current", c.UserController.Update)
+	users.Get("/_current", c.UserController.Current)
+	users.Post("/_email/confirm", c.UserController.ConfirmEmailChange)
 
 	// OTP
-	c.App.Post("/api/users/_otp/verify", c.OtpController.VerifyOtpUser)
+	users.Post("/_otp/verify", c.OtpController.VerifyOtpUser)
+	users.Post("/_otp/resend", c.OtpController.ResendVerification)
+
+	// Two-factor authentication
+	users.Post("/_2fa", c.TwoFactorController.Enroll)
+	users.Post("/_2fa/confirm", c.TwoFactorController.Confirm)
+	users.Post("/_2fa/recovery", c.TwoFactorController.RegenerateRecoveryCodes)
+	users.Delete("/_2fa", c.TwoFactorController.Disable)
+
+	// Personal access tokens
+	users.Post("/_tokens", c.AccessTokenController.Create)
+	users.Get("/_tokens", c.AccessTokenController.Get)
+	users.Delete("/_tokens", c.AccessTokenController.Delete)
+
+	// Address books, invitations are answered by the invited account
+	addressBooks := c.App.Group("/api/addressbooks", middleware.RequireSession())
+	addressBooks.Get("/_invitations", c.AddressBookController.GetInvitations)
+	addressBooks.Post("/_invitations/accept", c.AddressBookController.AcceptInvitation)
+	addressBooks.Post("/_invitations/decline", c.AddressBookController.DeclineInvitation)
+	addressBooks.Post("", c.AddressBookController.Create)
+	addressBooks.Get("", c.AddressBookController.Get)
+	addressBooks.Patch("/:id", c.AddressBookController.Update)
+	addressBooks.Delete("/:id", c.AddressBookController.Delete)
+	addressBooks.Get("/:id/members", c.AddressBookController.GetMembers)
+	addressBooks.Patch("/:id/members", c.AddressBookController.UpdateMember)
+	addressBooks.Delete("/:id/members", c.AddressBookController.DeleteMember)
+	addressBooks.Post("/:id/invitations", c.AddressBookController.Invite)
 
 	//Tags
-	c.App.Post("/api/tags", c.TagController.Create)
-	c.App.Get("/api/tags", c.TagController.Get)
-	c.App.Patch("/api/tags", c.TagController.Update)
-	c.App.Delete("/api/tags", c.TagController.Delete)
+	tags := c.App.Group("/api/tags", middleware.RequireScope("tags"))
+	tags.Post("", c.TagController.Create)
+	tags.Get("", c.TagController.Get)
+	tags.Patch("", c.TagController.Update)
+	tags.Delete("", c.TagController.Delete)
 
 	//Persons
-	c.App.Post("/api/persons", c.PersonController.Create)
-	c.App.Get("/api/persons", c.PersonController.Get)
-	c.App.Patch("/api/persons", c.PersonController.Update)
-	c.App.Delete("/api/persons", c.PersonController.Delete)
+	persons := c.App.Group("/api/persons", middleware.RequireScope("persons"))
+	persons.Post("", c.PersonController.Create)
+	persons.Get("", c.PersonController.Get)
+	persons.Get("/_similar", c.PersonController.GetSimilar)
+	persons.Get("/_facets", c.PersonController.GetFacets)
+	persons.Get("/:id", c.PersonController.GetByID)
+	persons.Patch("", c.PersonController.Update)
+	persons.Delete("/:id", c.PersonController.Delete)
 
 	//Relationships
-	c.App.Post("/api/relationships", c.RelationshipController.Create)
-	c.App.Get("/api/relationships", c.RelationshipController.Get)
-	c.App.Patch("/api/relationships", c.RelationshipController.Update)
-	c.App.Delete("/api/relationships", c.RelationshipController.Delete)
+	relationships := c.App.Group("/api/relationships", middleware.RequireScope("relationships"))
+	relationships.Post("", c.RelationshipController.Create)
+	relationships.Get("", c.RelationshipController.Get)
+	relationships.Patch("", c.RelationshipController.Update)
+	relationships.Delete("", c.RelationshipController.Delete)
 
 	//Phones
-	c.App.Post("/api/phones", c.PhoneController.Create)
-	c.App.Get("/api/phones", c.PhoneController.Get)
-	c.App.Patch("/api/phones", c.PhoneController.Update)
-	c.App.Delete("/api/phones", c.PhoneController.Delete)
+	phones := c.App.Group("/api/phones", middleware.RequireScope("phones"))
+	phones.Post("", c.PhoneController.Create)
+	phones.Get("", c.PhoneController.Get)
+	phones.Patch("", c.PhoneController.Update)
+	phones.Delete("", c.PhoneController.Delete)
 
 	//Important Dates
-	c.App.Post("/api/importantdates", c.ImportantDateController.Create)
-	c.App.Get("/api/importantdates", c.ImportantDateController.Get)
-	c.App.Patch("/api/importantdates", c.ImportantDateController.Update)
-	c.App.Delete("/api/importantdates", c.ImportantDateController.Delete)
+	importantDates := c.App.Group("/api/importantdates", middleware.RequireScope("dates"))
+	importantDates.Post("", c.ImportantDateController.Create)
+	importantDates.Get("", c.ImportantDateController.Get)
+	importantDates.Patch("", c.ImportantDateController.Update)
+	importantDates.Delete("", c.ImportantDateController.Delete)
+
+	//Smart lists, saved person queries
+	smartLists := c.App.Group("/api/smartlists", middleware.RequireScope("persons"))
+	smartLists.Post("", c.SmartListController.Create)
+	smartLists.Get("", c.SmartListController.Get)
+	smartLists.Get("/:id", c.SmartListController.GetByID)
+	smartLists.Patch("/:id", c.SmartListController.Update)
+	smartLists.Delete("/:id", c.SmartListController.Delete)
+	smartLists.Get("/:id/persons", c.SmartListController.Evaluate)
+
+	//Search, hit types are limited by the caller's scopes
+	c.App.Get("/api/search", c.SearchController.Search)
 }
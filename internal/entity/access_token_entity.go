@@ -0,0 +1,29 @@
+package entity
+
+import "strings"
+
+// AccessToken is a struct that represents a personal access token used by scripts and integrations
+type AccessToken struct {
+	ID         string `gorm:"column:id;primaryKey"`
+	UserID     string `gorm:"column:user_id;index;not null"`
+	Name       string `gorm:"column:name;not null"`
+	TokenHash  string `gorm:"column:token_hash;uniqueIndex;not null"`
+	Scopes     string `gorm:"column:scopes;not null"`
+	ExpiresAt  int64  `gorm:"column:expires_at"`
+	LastUsedAt int64  `gorm:"column:last_used_at"`
+	CreatedAt  int64  `gorm:"column:created_at;autoCreateTime:milli"`
+
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (u *AccessToken) TableName() string {
+	return "access_tokens"
+}
+
+// ScopeList returns the scopes, which are stored comma separated
+func (u *AccessToken) ScopeList() []string {
+	if u.Scopes == "" {
+		return nil
+	}
+	return strings.Split(u.Scopes, ",")
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// AddressBook is a struct that represents a shared book of persons, tags and relationships
+type AddressBook struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	Name      string    `gorm:"column:name;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;type:timestamptz"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;type:timestamptz"`
+
+	Members []AddressBookMember `gorm:"foreignKey:AddressBookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (u *AddressBook) TableName() string {
+	return "address_books"
+}
@@ -0,0 +1,19 @@
+package entity
+
+// AddressBookInvitation is a struct that represents a pending invitation to join an address book
+type AddressBookInvitation struct {
+	ID            string          `gorm:"column:id;primaryKey"`
+	AddressBookID string          `gorm:"column:address_book_id;index;not null"`
+	Email         string          `gorm:"column:email;index;not null"`
+	Role          AddressBookRole `gorm:"column:role;not null"`
+	TokenHash     string          `gorm:"column:token_hash;uniqueIndex;not null"`
+	InvitedBy     string          `gorm:"column:invited_by;not null"`
+	ExpiresAt     int64           `gorm:"column:expires_at;not null"`
+	CreatedAt     int64           `gorm:"column:created_at;autoCreateTime:milli"`
+
+	AddressBook *AddressBook `gorm:"foreignKey:AddressBookID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (u *AddressBookInvitation) TableName() string {
+	return "address_book_invitations"
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+type AddressBookRole string
+
+const (
+	AddressBookRoleOwner  AddressBookRole = "owner"
+	AddressBookRoleEditor AddressBookRole = "editor"
+	AddressBookRoleViewer AddressBookRole = "viewer"
+)
+
+var addressBookRoleRanks = map[AddressBookRole]int{
+	AddressBookRoleViewer: 1,
+	AddressBookRoleEditor: 2,
+	AddressBookRoleOwner:  3,
+}
+
+// Allows reports whether the role grants at least the rights of required
+func (r AddressBookRole) Allows(required AddressBookRole) bool {
+	return addressBookRoleRanks[r] >= addressBookRoleRanks[required]
+}
+
+// AddressBookMember is a struct that represents a user's role in an address book
+type AddressBookMember struct {
+	ID            string          `gorm:"column:id;primaryKey"`
+	AddressBookID string          `gorm:"column:address_book_id;uniqueIndex:idx_address_book_members_book_user;not null"`
+	UserID        string          `gorm:"column:user_id;uniqueIndex:idx_address_book_members_book_user;index;not null"`
+	Role          AddressBookRole `gorm:"column:role;not null"`
+	CreatedAt     time.Time       `gorm:"column:created_at;autoCreateTime;type:timestamptz"`
+
+	AddressBook *AddressBook `gorm:"foreignKey:AddressBookID;references:ID"`
+	User        *User        `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (u *AddressBookMember) TableName() string {
+	return "address_book_members"
+}
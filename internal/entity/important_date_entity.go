@@ -6,9 +6,9 @@ import (
 
 type ImportantDate struct {
 	ID        string    `gorm:"column:id;primaryKey"`
-	Name      string    `gorm:"column:name;unique"`
+	Name      string    `gorm:"column:name;uniqueIndex:idx_important_dates_person_id_name,priority:2"`
 	Date      string    `gorm:"column:date;type:timestamptz"`
-	PersonID  string    `gorm:"column:person_id"`
+	PersonID  string    `gorm:"column:person_id;uniqueIndex:idx_important_dates_person_id_name,priority:1"`
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;type:timestamptz"`
 	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;type:timestamptz"`
 
@@ -18,3 +18,13 @@ type ImportantDate struct {
 func (u *ImportantDate) TableName() string {
 	return "important_dates"
 }
+
+func (u *ImportantDate) QueryFields() QueryFields {
+	return QueryFields{
+		Filter:    []string{"id", "name", "person_id"},
+		Sort:      []string{"name", "date", "created_at", "updated_at"},
+		DateRange: []string{"date", "created_at", "updated_at"},
+		Select:    []string{"id", "name", "date", "person_id", "created_at", "updated_at"},
+		Preload:   []string{"Person", "Person.Tags"},
+	}
+}
@@ -0,0 +1,14 @@
+package entity
+
+// LoginAttempt is a struct that tracks failed logins for one throttle key,
+// such as an account email or a client IP
+type LoginAttempt struct {
+	ID            string `gorm:"column:id;primaryKey"`
+	Failures      int    `gorm:"column:failures"`
+	LastFailureAt int64  `gorm:"column:last_failure_at"`
+	LockedUntil   int64  `gorm:"column:locked_until"`
+}
+
+func (u *LoginAttempt) TableName() string {
+	return "login_attempts"
+}
@@ -0,0 +1,17 @@
+package entity
+
+// OidcIdentity is a struct that links an external OpenID Connect account to a user
+type OidcIdentity struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserID    string `gorm:"column:user_id;index;not null"`
+	Provider  string `gorm:"column:provider;uniqueIndex:idx_oidc_identities_provider_subject;not null"`
+	Subject   string `gorm:"column:subject;uniqueIndex:idx_oidc_identities_provider_subject;not null"`
+	Email     string `gorm:"column:email"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (u *OidcIdentity) TableName() string {
+	return "oidc_identities"
+}
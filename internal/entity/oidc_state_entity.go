@@ -0,0 +1,17 @@
+package entity
+
+// OidcState is a struct that represents a pending authorization request, kept
+// until the provider redirects back with the matching state
+type OidcState struct {
+	ID           string `gorm:"column:id;primaryKey"`
+	StateHash    string `gorm:"column:state_hash;uniqueIndex;not null"`
+	Provider     string `gorm:"column:provider;not null"`
+	CodeVerifier string `gorm:"column:code_verifier;not null"`
+	Nonce        string `gorm:"column:nonce;not null"`
+	ExpiresAt    int64  `gorm:"column:expires_at;not null"`
+	CreatedAt    int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (u *OidcState) TableName() string {
+	return "oidc_states"
+}
@@ -1,14 +1,26 @@
 package entity
 
+// OtpPurpose scopes an OTP to the single flow it was issued for
+type OtpPurpose string
+
+const (
+	OtpPurposeEmailVerification OtpPurpose = "email_verification"
+	OtpPurposePasswordReset     OtpPurpose = "password_reset"
+	OtpPurposeEmailChange       OtpPurpose = "email_change"
+	OtpPurposeAccountDeletion   OtpPurpose = "account_deletion"
+)
+
 // Otp is a struct that represents a otp entity
 type Otp struct {
-	ID         string `gorm:"column:id;primaryKey"`
-	Otp        string `gorm:"column:otp;not null"`
-	Token      string `gorm:"column:token"`
-	UserID     string `gorm:"column:user_id"`
-	VerifiedAt int64  `gorm:"column:verified_at"`
-	ExpiresAt  int64  `gorm:"column:expires_at"`
-	CreatedAt  int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	ID         string     `gorm:"column:id;primaryKey"`
+	Otp        string     `gorm:"column:otp;not null"`
+	Token      string     `gorm:"column:token"`
+	Purpose    OtpPurpose `gorm:"column:purpose;index;not null;default:email_verification"`
+	UserID     string     `gorm:"column:user_id"`
+	VerifiedAt int64      `gorm:"column:verified_at"`
+	Attempts   int        `gorm:"column:attempts"`
+	ExpiresAt  int64      `gorm:"column:expires_at"`
+	CreatedAt  int64      `gorm:"column:created_at;autoCreateTime:milli"`
 
 	User *User `gorm:"foreignKey:UserID;references:ID"`
 }
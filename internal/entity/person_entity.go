@@ -4,22 +4,44 @@ import (
 	"time"
 )
 
+// Person belongs to the address book AddressBookID; UserID is the member who created it
 type Person struct {
-	ID          string    `gorm:"column:id;primaryKey"`
-	FirstName   string    `gorm:"column:first_name"`
-	LastName    string    `gorm:"column:last_name"`
-	Nickname    string    `gorm:"column:nickname"`
-	Avatar      string    `gorm:"column:avatar"`
-	Description string    `gorm:"column:description"`
-	UserID      string    `gorm:"column:user_id"`
-	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime;type:timestamptz"`
-	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime;type:timestamptz"`
+	ID            string    `gorm:"column:id;primaryKey"`
+	FirstName     string    `gorm:"column:first_name"`
+	LastName      string    `gorm:"column:last_name"`
+	Nickname      string    `gorm:"column:nickname"`
+	Avatar        string    `gorm:"column:avatar"`
+	Description   string    `gorm:"column:description"`
+	UserID        string    `gorm:"column:user_id"`
+	AddressBookID string    `gorm:"column:address_book_id;index"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime;type:timestamptz"`
+	UpdatedAt     time.Time `gorm:"column:updated_at;autoUpdateTime;type:timestamptz"`
 
-	Tags          []Tag          `gorm:"many2many:persons_tags"`
-	Relationships []Relationship `gorm:"many2many:persons_relationships"`
-	User          *User          `gorm:"foreignKey:UserID;references:ID"`
+	Tags           []Tag           `gorm:"many2many:persons_tags"`
+	Relationships  []Relationship  `gorm:"many2many:persons_relationships"`
+	User           *User           `gorm:"foreignKey:UserID;references:ID"`
+	AddressBook    *AddressBook    `gorm:"foreignKey:AddressBookID;references:ID"`
+	Phones         []Phone         `gorm:"foreignKey:PersonID"`
+	ImportantDates []ImportantDate `gorm:"foreignKey:PersonID"`
 }
 
 func (u *Person) TableName() string {
 	return "persons"
 }
+
+func (u *Person) QueryFields() QueryFields {
+	return QueryFields{
+		Filter:    []string{"id", "first_name", "last_name", "nickname", "description", "user_id", "address_book_id"},
+		Fuzzy:     []string{"first_name", "last_name", "nickname"},
+		Sort:      []string{"first_name", "last_name", "nickname", "created_at", "updated_at"},
+		DateRange: []string{"created_at", "updated_at"},
+		Select:    []string{"id", "first_name", "last_name", "nickname", "avatar", "description", "user_id", "address_book_id", "created_at", "updated_at"},
+		Preload:   []string{"Tags", "Tags.Persons", "Relationships", "Relationships.Persons", "Phones", "ImportantDates"},
+		Relations: []QueryRelation{
+			{Name: "tags", Table: "tags", JoinTable: "persons_tags", ForeignKey: "person_id", RelatedKey: "tag_id", Fields: (&Tag{}).QueryFields()},
+			{Name: "relationships", Table: "relationships", JoinTable: "persons_relationships", ForeignKey: "person_id", RelatedKey: "relationship_id", Fields: (&Relationship{}).QueryFields()},
+			{Name: "phones", Table: "phones", ForeignKey: "person_id", Fields: (&Phone{}).QueryFields()},
+			{Name: "important_dates", Table: "important_dates", ForeignKey: "person_id", Fields: (&ImportantDate{}).QueryFields()},
+		},
+	}
+}
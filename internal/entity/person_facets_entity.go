@@ -0,0 +1,21 @@
+package entity
+
+// PersonFacets counts the persons a list query matches, grouped the ways the
+// contact sidebar shows them. It is read from aggregate queries and has no
+// table of its own.
+type PersonFacets struct {
+	Total            int64  `gorm:"column:total"`
+	WithPhone        *int64 `gorm:"column:with_phone"`         // nil when phones are omitted
+	WithUpcomingDate *int64 `gorm:"column:with_upcoming_date"` // nil when important dates are omitted
+
+	Tags          []FacetCount `gorm:"-"`
+	Relationships []FacetCount `gorm:"-"`
+	CreatedMonths []FacetCount `gorm:"-"` // ID is the month as YYYY-MM
+}
+
+// FacetCount is how many matching persons share one facet value
+type FacetCount struct {
+	ID    string `gorm:"column:id"`
+	Name  string `gorm:"column:name"`
+	Count int64  `gorm:"column:count"`
+}
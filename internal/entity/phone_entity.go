@@ -7,8 +7,8 @@ import (
 type Phone struct {
 	ID        string    `gorm:"column:id;primaryKey"`
 	Name      string    `gorm:"column:name"`
-	Number    string    `gorm:"column:number;unique"`
-	PersonID  string    `gorm:"column:person_id"`
+	Number    string    `gorm:"column:number;uniqueIndex:idx_phones_person_id_number,priority:2"`
+	PersonID  string    `gorm:"column:person_id;uniqueIndex:idx_phones_person_id_number,priority:1"`
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;type:timestamptz"`
 	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;type:timestamptz"`
 
@@ -18,3 +18,13 @@ type Phone struct {
 func (u *Phone) TableName() string {
 	return "phones"
 }
+
+func (u *Phone) QueryFields() QueryFields {
+	return QueryFields{
+		Filter:    []string{"id", "name", "number", "person_id"},
+		Sort:      []string{"name", "number", "created_at", "updated_at"},
+		DateRange: []string{"created_at", "updated_at"},
+		Select:    []string{"id", "name", "number", "person_id", "created_at", "updated_at"},
+		Preload:   []string{"Person", "Person.Tags"},
+	}
+}
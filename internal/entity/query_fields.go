@@ -0,0 +1,63 @@
+package entity
+
+import "slices"
+
+// QueryFields whitelists the columns and relations a generic query may
+// reference for an entity. Anything else is rejected before reaching SQL.
+// Fuzzy fields have a trigram index and can be searched by similarity.
+// Select lists the columns a sparse fieldset may pick, and Preload may name
+// nested relations with dots, e.g. "Relationships.Persons".
+type QueryFields struct {
+	Filter    []string
+	Fuzzy     []string
+	Sort      []string
+	DateRange []string
+	Select    []string
+	Preload   []string
+	Relations []QueryRelation
+}
+
+// QueryRelation is a related table whose rows can filter the entity. Rows of
+// Table point at the entity through ForeignKey, or for a many2many through
+// JoinTable, whose ForeignKey points at the entity and RelatedKey at Table.
+type QueryRelation struct {
+	Name       string
+	Table      string
+	JoinTable  string
+	ForeignKey string
+	RelatedKey string
+	Fields     QueryFields
+}
+
+func (f QueryFields) Relation(name string) (QueryRelation, bool) {
+	for _, relation := range f.Relations {
+		if relation.Name == name {
+			return relation, true
+		}
+	}
+	return QueryRelation{}, false
+}
+
+func (f QueryFields) AllowsFilter(field string) bool {
+	return slices.Contains(f.Filter, field)
+}
+
+func (f QueryFields) AllowsFuzzy(field string) bool {
+	return slices.Contains(f.Fuzzy, field)
+}
+
+func (f QueryFields) AllowsSort(field string) bool {
+	return slices.Contains(f.Sort, field)
+}
+
+func (f QueryFields) AllowsDateRange(field string) bool {
+	return slices.Contains(f.DateRange, field)
+}
+
+func (f QueryFields) AllowsSelect(field string) bool {
+	return slices.Contains(f.Select, field)
+}
+
+func (f QueryFields) AllowsPreload(relation string) bool {
+	return slices.Contains(f.Preload, relation)
+}
@@ -0,0 +1,16 @@
+package entity
+
+// RecoveryCode is a struct that represents a one-time two-factor recovery code
+type RecoveryCode struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserID    string `gorm:"column:user_id;index;not null"`
+	CodeHash  string `gorm:"column:code_hash;not null"`
+	UsedAt    int64  `gorm:"column:used_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (u *RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
@@ -0,0 +1,21 @@
+package entity
+
+// RefreshToken is a struct that represents an opaque, single-use refresh token.
+// Tokens issued from the same login share a SessionID, which acts as the token family.
+// Its times are Unix milliseconds; ExpiresAt is copied from the session.
+type RefreshToken struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	TokenHash string `gorm:"column:token_hash;uniqueIndex;not null"`
+	SessionID string `gorm:"column:session_id;index;not null"`
+	UserID    string `gorm:"column:user_id;not null"`
+	ExpiresAt int64  `gorm:"column:expires_at"`
+	RotatedAt int64  `gorm:"column:rotated_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+
+	Session *Session `gorm:"foreignKey:SessionID;references:ID"`
+	User    *User    `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (u *RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
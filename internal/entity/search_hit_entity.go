@@ -0,0 +1,20 @@
+package entity
+
+// SearchHit types
+const (
+	SearchHitPerson        = "person"
+	SearchHitPhone         = "phone"
+	SearchHitTag           = "tag"
+	SearchHitImportantDate = "important_date"
+)
+
+// SearchHit is a ranked full-text search match. It is read from a query
+// across several tables and has no table of its own.
+type SearchHit struct {
+	Type     string  `gorm:"column:type"`
+	ID       string  `gorm:"column:id"`
+	PersonID string  `gorm:"column:person_id"`
+	Title    string  `gorm:"column:title"`
+	Snippet  string  `gorm:"column:snippet"`
+	Rank     float64 `gorm:"column:rank"`
+}
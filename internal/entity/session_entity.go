@@ -0,0 +1,17 @@
+package entity
+
+// Session is a struct that represents a login session, keyed by the JWT jti.
+// Its times are Unix milliseconds.
+type Session struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserID    string `gorm:"column:user_id;index;not null"`
+	ExpiresAt int64  `gorm:"column:expires_at"`
+	RevokedAt int64  `gorm:"column:revoked_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (u *Session) TableName() string {
+	return "sessions"
+}
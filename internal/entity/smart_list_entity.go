@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// SmartList is a named person list query a user saved to run again against
+// current data. Pinned lists are shown on the dashboard by Position.
+type SmartList struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	UserID    string    `gorm:"column:user_id;uniqueIndex:idx_smart_lists_user_id_name,priority:1;not null"`
+	Name      string    `gorm:"column:name;uniqueIndex:idx_smart_lists_user_id_name,priority:2;not null"`
+	Query     string    `gorm:"column:query;type:jsonb;not null"` // a model.Query as JSON
+	Pinned    bool      `gorm:"column:pinned;not null;default:false"`
+	Position  int       `gorm:"column:position;not null;default:0"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;type:timestamptz"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;type:timestamptz"`
+
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (u *SmartList) TableName() string {
+	return "smart_lists"
+}
@@ -5,16 +5,28 @@ import (
 )
 
 type Tag struct {
-	ID        string    `gorm:"column:id;primaryKey"`
-	Name      string    `gorm:"column:name;unique"`
-	UserID    string    `gorm:"column:user_id"`
-	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;type:timestamptz"`
-	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;type:timestamptz"`
+	ID            string    `gorm:"column:id;primaryKey"`
+	Name          string    `gorm:"column:name;uniqueIndex:idx_tags_address_book_id_name,priority:2"`
+	UserID        string    `gorm:"column:user_id"`
+	AddressBookID string    `gorm:"column:address_book_id;uniqueIndex:idx_tags_address_book_id_name,priority:1"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime;type:timestamptz"`
+	UpdatedAt     time.Time `gorm:"column:updated_at;autoUpdateTime;type:timestamptz"`
 
-	Persons []Person `gorm:"many2many:persons_tags"`
-	User    *User    `gorm:"foreignKey:UserID;references:ID"`
+	Persons     []Person     `gorm:"many2many:persons_tags"`
+	User        *User        `gorm:"foreignKey:UserID;references:ID"`
+	AddressBook *AddressBook `gorm:"foreignKey:AddressBookID;references:ID"`
 }
 
 func (u *Tag) TableName() string {
 	return "tags"
 }
+
+func (u *Tag) QueryFields() QueryFields {
+	return QueryFields{
+		Filter:    []string{"id", "name", "user_id", "address_book_id"},
+		Sort:      []string{"name", "created_at", "updated_at"},
+		DateRange: []string{"created_at", "updated_at"},
+		Select:    []string{"id", "name", "user_id", "address_book_id", "created_at", "updated_at"},
+		Preload:   []string{"Persons", "Persons.Relationships"},
+	}
+}
@@ -0,0 +1,17 @@
+package entity
+
+// TwoFactorChallenge is a struct that represents a password login waiting for its second factor
+type TwoFactorChallenge struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	TokenHash string `gorm:"column:token_hash;uniqueIndex;not null"`
+	UserID    string `gorm:"column:user_id;not null"`
+	ExpiresAt int64  `gorm:"column:expires_at"`
+	Attempts  int    `gorm:"column:attempts"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+
+	User *User `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (u *TwoFactorChallenge) TableName() string {
+	return "two_factor_challenges"
+}
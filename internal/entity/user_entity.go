@@ -2,15 +2,20 @@ package entity
 
 // User is a struct that represents a user entity
 type User struct {
-	ID         string `gorm:"column:id;primaryKey"`
-	Email      string `gorm:"column:email;unique;not null"`
-	Password   string `gorm:"column:password;not null"`
-	Name       string `gorm:"column:name"`
-	Avatar     string `gorm:"column:avatar"`
-	VerifiedAt int64  `gorm:"column:verified_at"`
-	CreatedAt  int64  `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt  int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	Token      string `gorm:"-"`
+	ID            string `gorm:"column:id;primaryKey"`
+	Email         string `gorm:"column:email;unique;not null"`
+	PendingEmail  string `gorm:"column:pending_email"`
+	Password      string `gorm:"column:password;not null"`
+	Name          string `gorm:"column:name"`
+	Avatar        string `gorm:"column:avatar"`
+	VerifiedAt    int64  `gorm:"column:verified_at"`
+	TotpSecret    string `gorm:"column:totp_secret"`
+	TotpEnabledAt int64  `gorm:"column:totp_enabled_at"`
+	TotpLastStep  int64  `gorm:"column:totp_last_step"`
+	CreatedAt     int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt     int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	Token         string `gorm:"-"`
+	RefreshToken  string `gorm:"-"`
 
 	Otps    []Otp    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 	Persons []Person `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
@@ -0,0 +1,34 @@
+package model
+
+// AccessTokenPrefix marks bearer tokens that are personal access tokens rather than JWTs
+const AccessTokenPrefix = "rlpat_"
+
+// AccessTokenResources are the resources a personal access token can be scoped to,
+// each as "<resource>:read" or "<resource>:write"
+var AccessTokenResources = []string{"persons", "tags", "relationships", "phones", "dates"}
+
+type AccessTokenResponse struct {
+	ID         string   `json:"id,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
+	Token      string   `json:"token,omitempty"`
+	ExpiresAt  int64    `json:"expires_at,omitempty"`
+	LastUsedAt int64    `json:"last_used_at,omitempty"`
+	CreatedAt  int64    `json:"created_at,omitempty"`
+}
+
+type CreateAccessTokenRequest struct {
+	UserID        string   `json:"-" validate:"required"`
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type GetAccessTokenRequest struct {
+	UserID string `json:"-" validate:"required"`
+}
+
+type DeleteAccessTokenRequest struct {
+	ID     string `json:"id" validate:"required"`
+	UserID string `json:"-" validate:"required"`
+}
@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+type AddressBookResponse struct {
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+type AddressBookMemberResponse struct {
+	UserID    string    `json:"user_id,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+type AddressBookInvitationResponse struct {
+	ID              string `json:"id,omitempty"`
+	AddressBookID   string `json:"address_book_id,omitempty"`
+	AddressBookName string `json:"address_book_name,omitempty"`
+	Email           string `json:"email,omitempty"`
+	Role            string `json:"role,omitempty"`
+	ExpiresAt       int64  `json:"expires_at,omitempty"`
+	CreatedAt       int64  `json:"created_at,omitempty"`
+}
+
+type CreateAddressBookRequest struct {
+	UserID string `json:"-" validate:"required"`
+	Name   string `json:"name" validate:"required,max=100"`
+}
+
+type GetAddressBookRequest struct {
+	UserID string `json:"-" validate:"required"`
+}
+
+type UpdateAddressBookRequest struct {
+	ID     string `json:"-" validate:"required"`
+	UserID string `json:"-" validate:"required"`
+	Name   string `json:"name" validate:"required,max=100"`
+}
+
+type DeleteAddressBookRequest struct {
+	ID     string `json:"-" validate:"required"`
+	UserID string `json:"-" validate:"required"`
+}
+
+type GetAddressBookMembersRequest struct {
+	AddressBookID string `json:"-" validate:"required"`
+	UserID        string `json:"-" validate:"required"`
+}
+
+type UpdateAddressBookMemberRequest struct {
+	AddressBookID string `json:"-" validate:"required"`
+	UserID        string `json:"-" validate:"required"`
+	MemberID      string `json:"user_id" validate:"required"`
+	Role          string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type DeleteAddressBookMemberRequest struct {
+	AddressBookID string `json:"-" validate:"required"`
+	UserID        string `json:"-" validate:"required"`
+	MemberID      string `json:"user_id" validate:"required"`
+}
+
+type CreateAddressBookInvitationRequest struct {
+	AddressBookID string `json:"-" validate:"required"`
+	UserID        string `json:"-" validate:"required"`
+	Email         string `json:"email" validate:"required,email"`
+	Role          string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type GetAddressBookInvitationRequest struct {
+	UserID string `json:"-" validate:"required"`
+}
+
+type RespondAddressBookInvitationRequest struct {
+	UserID string `json:"-" validate:"required"`
+	Token  string `json:"token" validate:"required"`
+}
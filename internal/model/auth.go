@@ -3,4 +3,26 @@ package model
 type Auth struct {
 	// Login user id
 	ID string
+	// Session id (JWT jti) of the current token
+	SessionID string
+	// Personal access token id, empty for session tokens
+	AccessTokenID string
+	// Scopes granted to the personal access token
+	Scopes []string
+	// Whether the user has confirmed their email address
+	Verified bool
+}
+
+// HasScope reports whether the caller may use scope. Session tokens carry
+// every scope; personal access tokens only those they were issued with.
+func (a *Auth) HasScope(scope string) bool {
+	if a.AccessTokenID == "" {
+		return true
+	}
+	for _, granted := range a.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
 }
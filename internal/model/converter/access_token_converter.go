@@ -0,0 +1,35 @@
+package converter
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+)
+
+func AccessTokenToResponse(token *entity.AccessToken) *model.AccessTokenResponse {
+	if token == nil {
+		return nil
+	}
+
+	return &model.AccessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Scopes:     token.ScopeList(),
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+func AccessTokensToResponses(tokens *[]entity.AccessToken) *[]model.AccessTokenResponse {
+	if tokens == nil {
+		return nil
+	}
+
+	responses := make([]model.AccessTokenResponse, 0, len(*tokens))
+
+	for _, token := range *tokens {
+		responses = append(responses, *AccessTokenToResponse(&token))
+	}
+
+	return &responses
+}
@@ -0,0 +1,102 @@
+package converter
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+)
+
+// AddressBookToResponse describes book as seen by a member with role
+func AddressBookToResponse(book *entity.AddressBook, role entity.AddressBookRole) *model.AddressBookResponse {
+	if book == nil {
+		return nil
+	}
+
+	return &model.AddressBookResponse{
+		ID:        book.ID,
+		Name:      book.Name,
+		Role:      string(role),
+		CreatedAt: book.CreatedAt,
+		UpdatedAt: book.UpdatedAt,
+	}
+}
+
+func MembershipsToAddressBookResponses(members *[]entity.AddressBookMember) *[]model.AddressBookResponse {
+	if members == nil {
+		return nil
+	}
+
+	responses := make([]model.AddressBookResponse, 0, len(*members))
+
+	for _, member := range *members {
+		if member.AddressBook == nil {
+			continue
+		}
+		responses = append(responses, *AddressBookToResponse(member.AddressBook, member.Role))
+	}
+
+	return &responses
+}
+
+func AddressBookMemberToResponse(member *entity.AddressBookMember) *model.AddressBookMemberResponse {
+	if member == nil {
+		return nil
+	}
+
+	response := &model.AddressBookMemberResponse{
+		UserID:    member.UserID,
+		Role:      string(member.Role),
+		CreatedAt: member.CreatedAt,
+	}
+	if member.User != nil {
+		response.Email = member.User.Email
+		response.Name = member.User.Name
+	}
+	return response
+}
+
+func AddressBookMembersToResponses(members *[]entity.AddressBookMember) *[]model.AddressBookMemberResponse {
+	if members == nil {
+		return nil
+	}
+
+	responses := make([]model.AddressBookMemberResponse, 0, len(*members))
+
+	for _, member := range *members {
+		responses = append(responses, *AddressBookMemberToResponse(&member))
+	}
+
+	return &responses
+}
+
+func AddressBookInvitationToResponse(invitation *entity.AddressBookInvitation) *model.AddressBookInvitationResponse {
+	if invitation == nil {
+		return nil
+	}
+
+	response := &model.AddressBookInvitationResponse{
+		ID:            invitation.ID,
+		AddressBookID: invitation.AddressBookID,
+		Email:         invitation.Email,
+		Role:          string(invitation.Role),
+		ExpiresAt:     invitation.ExpiresAt,
+		CreatedAt:     invitation.CreatedAt,
+	}
+	if invitation.AddressBook != nil {
+		response.AddressBookName = invitation.AddressBook.Name
+	}
+	return response
+}
+
+func AddressBookInvitationsToResponses(invitations *[]entity.AddressBookInvitation) *[]model.AddressBookInvitationResponse {
+	if invitations == nil {
+		return nil
+	}
+
+	responses := make([]model.AddressBookInvitationResponse, 0, len(*invitations))
+
+	for _, invitation := range *invitations {
+		responses = append(responses, *AddressBookInvitationToResponse(&invitation))
+	}
+
+	return &responses
+}
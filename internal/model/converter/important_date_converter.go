@@ -42,3 +42,11 @@ func ImportantDatesToResponses(importantDates *[]entity.ImportantDate) *[]model.
 
 	return &responses
 }
+
+// importantDateResponses converts loaded important dates, nil when none were loaded
+func importantDateResponses(importantDates []entity.ImportantDate) []model.ImportantDateResponse {
+	if len(importantDates) == 0 {
+		return nil
+	}
+	return *ImportantDatesToResponses(&importantDates)
+}
@@ -14,6 +14,7 @@ func OtpToResponse(otp *entity.Otp) *model.OtpResponse {
 		ID:        otp.ID,
 		UserID:    otp.UserID,
 		Token:     otp.Token,
+		Purpose:   string(otp.Purpose),
 		VerfiedAt: otp.VerifiedAt,
 		ExpiresAt: otp.ExpiresAt,
 		CreatedAt: otp.CreatedAt,
@@ -11,16 +11,21 @@ func PersonToResponse(person *entity.Person) *model.PersonResponse {
 	}
 
 	return &model.PersonResponse{
-		ID:          person.ID,
-		FirstName:   person.FirstName,
-		LastName:    person.LastName,
-		Nickname:    person.Nickname,
-		Avatar:      person.Avatar,
-		Description: person.Description,
-		UserID:      person.UserID,
-		CreatedAt:   person.CreatedAt,
-		UpdatedAt:   person.UpdatedAt,
-		User:        UserToResponse(person.User),
+		ID:             person.ID,
+		FirstName:      person.FirstName,
+		LastName:       person.LastName,
+		Nickname:       person.Nickname,
+		Avatar:         person.Avatar,
+		Description:    person.Description,
+		UserID:         person.UserID,
+		AddressBookID:  person.AddressBookID,
+		CreatedAt:      person.CreatedAt,
+		UpdatedAt:      person.UpdatedAt,
+		Tags:           tagResponses(person.Tags),
+		Relationships:  relationshipResponses(person.Relationships),
+		Phones:         phoneResponses(person.Phones),
+		ImportantDates: importantDateResponses(person.ImportantDates),
+		User:           UserToResponse(person.User),
 	}
 }
 
@@ -32,17 +37,32 @@ func PersonsToResponses(persons *[]entity.Person) *[]model.PersonResponse {
 	responses := make([]model.PersonResponse, 0, len(*persons))
 
 	for _, person := range *persons {
-		responses = append(responses, model.PersonResponse{
-			ID:          person.ID,
-			FirstName:   person.FirstName,
-			LastName:    person.LastName,
-			Nickname:    person.Nickname,
-			Avatar:      person.Avatar,
-			Description: person.Description,
-			UserID:      person.UserID,
-			CreatedAt:   person.CreatedAt,
-			UpdatedAt:   person.UpdatedAt,
-			User:        UserToResponse(person.User),
+		responses = append(responses, *PersonToResponse(&person))
+	}
+
+	return &responses
+}
+
+// personResponses converts loaded persons, nil when none were loaded
+func personResponses(persons []entity.Person) []model.PersonResponse {
+	if len(persons) == 0 {
+		return nil
+	}
+	return *PersonsToResponses(&persons)
+}
+
+// PersonsToSimilarResponses pairs each person with its similarity
+func PersonsToSimilarResponses(persons *[]entity.Person, similarities []float64) *[]model.SimilarPersonResponse {
+	if persons == nil {
+		return nil
+	}
+
+	responses := make([]model.SimilarPersonResponse, 0, len(*persons))
+
+	for i, person := range *persons {
+		responses = append(responses, model.SimilarPersonResponse{
+			PersonResponse: *PersonToResponse(&person),
+			Similarity:     similarities[i],
 		})
 	}
 
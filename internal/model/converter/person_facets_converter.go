@@ -0,0 +1,35 @@
+package converter
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+)
+
+func PersonFacetsToResponse(facets *entity.PersonFacets, upcomingDays int) *model.PersonFacetsResponse {
+	if facets == nil {
+		return nil
+	}
+
+	months := make([]model.MonthCountResponse, 0, len(facets.CreatedMonths))
+	for _, month := range facets.CreatedMonths {
+		months = append(months, model.MonthCountResponse{Month: month.ID, Count: month.Count})
+	}
+
+	return &model.PersonFacetsResponse{
+		Total:            facets.Total,
+		WithPhone:        facets.WithPhone,
+		WithUpcomingDate: facets.WithUpcomingDate,
+		UpcomingDays:     upcomingDays,
+		Tags:             facetCountResponses(facets.Tags),
+		Relationships:    facetCountResponses(facets.Relationships),
+		CreatedMonths:    months,
+	}
+}
+
+func facetCountResponses(counts []entity.FacetCount) []model.FacetCountResponse {
+	responses := make([]model.FacetCountResponse, 0, len(counts))
+	for _, count := range counts {
+		responses = append(responses, model.FacetCountResponse{ID: count.ID, Name: count.Name, Count: count.Count})
+	}
+	return responses
+}
@@ -42,3 +42,11 @@ func PhonesToResponses(phones *[]entity.Phone) *[]model.PhoneResponse {
 
 	return &responses
 }
+
+// phoneResponses converts loaded phones, nil when none were loaded
+func phoneResponses(phones []entity.Phone) []model.PhoneResponse {
+	if len(phones) == 0 {
+		return nil
+	}
+	return *PhonesToResponses(&phones)
+}
@@ -11,13 +11,15 @@ func RelationshipToResponse(relationship *entity.Relationship) *model.Relationsh
 	}
 
 	return &model.RelationshipResponse{
-		ID:        relationship.ID,
-		Name:      relationship.Name,
-		Color:     relationship.Color,
-		UserID:    relationship.UserID,
-		CreatedAt: relationship.CreatedAt,
-		UpdatedAt: relationship.UpdatedAt,
-		User:      UserToResponse(relationship.User),
+		ID:            relationship.ID,
+		Name:          relationship.Name,
+		Color:         relationship.Color,
+		UserID:        relationship.UserID,
+		AddressBookID: relationship.AddressBookID,
+		CreatedAt:     relationship.CreatedAt,
+		UpdatedAt:     relationship.UpdatedAt,
+		Persons:       relationshipPersons(relationship.Persons),
+		User:          UserToResponse(relationship.User),
 	}
 }
 
@@ -29,17 +31,24 @@ func RelationshipsToResponses(relationships *[]entity.Relationship) *[]model.Rel
 	responses := make([]model.RelationshipResponse, 0, len(*relationships))
 
 	for _, relationship := range *relationships {
-		responses = append(responses, model.RelationshipResponse{
-			ID:        relationship.ID,
-			Name:      relationship.Name,
-			Color:     relationship.Color,
-			UserID:    relationship.UserID,
-			CreatedAt: relationship.CreatedAt,
-			UpdatedAt: relationship.UpdatedAt,
-			Persons:   PersonsToResponses(&relationship.Persons),
-			User:      UserToResponse(relationship.User),
-		})
+		responses = append(responses, *RelationshipToResponse(&relationship))
 	}
 
 	return &responses
 }
+
+// relationshipResponses converts loaded relationships, nil when none were loaded
+func relationshipResponses(relationships []entity.Relationship) []model.RelationshipResponse {
+	if len(relationships) == 0 {
+		return nil
+	}
+	return *RelationshipsToResponses(&relationships)
+}
+
+// relationshipPersons converts loaded persons, nil when none were loaded
+func relationshipPersons(persons []entity.Person) *[]model.PersonResponse {
+	if len(persons) == 0 {
+		return nil
+	}
+	return PersonsToResponses(&persons)
+}
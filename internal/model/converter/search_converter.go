@@ -0,0 +1,34 @@
+package converter
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+)
+
+func SearchHitToResponse(hit *entity.SearchHit) *model.SearchHitResponse {
+	if hit == nil {
+		return nil
+	}
+
+	return &model.SearchHitResponse{
+		Type:     hit.Type,
+		ID:       hit.ID,
+		PersonID: hit.PersonID,
+		Title:    hit.Title,
+		Snippet:  hit.Snippet,
+		Rank:     hit.Rank,
+	}
+}
+
+func SearchHitsToResponses(hits *[]entity.SearchHit) *[]model.SearchHitResponse {
+	if hits == nil {
+		return nil
+	}
+
+	responses := make([]model.SearchHitResponse, 0, len(*hits))
+	for _, hit := range *hits {
+		responses = append(responses, *SearchHitToResponse(&hit))
+	}
+
+	return &responses
+}
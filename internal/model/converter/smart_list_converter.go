@@ -0,0 +1,38 @@
+package converter
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"encoding/json"
+)
+
+func SmartListToResponse(list *entity.SmartList) *model.SmartListResponse {
+	if list == nil {
+		return nil
+	}
+
+	response := &model.SmartListResponse{
+		ID:        list.ID,
+		Name:      list.Name,
+		Pinned:    list.Pinned,
+		Position:  list.Position,
+		CreatedAt: list.CreatedAt,
+		UpdatedAt: list.UpdatedAt,
+	}
+	// The query was checked when it was saved
+	_ = json.Unmarshal([]byte(list.Query), &response.Query)
+	return response
+}
+
+func SmartListsToResponses(lists *[]entity.SmartList) *[]model.SmartListResponse {
+	if lists == nil {
+		return nil
+	}
+
+	responses := make([]model.SmartListResponse, 0, len(*lists))
+	for _, list := range *lists {
+		responses = append(responses, *SmartListToResponse(&list))
+	}
+
+	return &responses
+}
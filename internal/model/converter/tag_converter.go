@@ -11,13 +11,14 @@ func TagToResponse(tag *entity.Tag) *model.TagResponse {
 	}
 
 	return &model.TagResponse{
-		ID:        tag.ID,
-		Name:      tag.Name,
-		UserID:    tag.UserID,
-		CreatedAt: tag.CreatedAt,
-		UpdatedAt: tag.UpdatedAt,
-		//Persons:   tag.Persons,
-		User: UserToResponse(tag.User),
+		ID:            tag.ID,
+		Name:          tag.Name,
+		UserID:        tag.UserID,
+		AddressBookID: tag.AddressBookID,
+		CreatedAt:     tag.CreatedAt,
+		UpdatedAt:     tag.UpdatedAt,
+		Persons:       personResponses(tag.Persons),
+		User:          UserToResponse(tag.User),
 	}
 }
 
@@ -29,16 +30,16 @@ func TagsToResponses(tags *[]entity.Tag) *[]model.TagResponse {
 	responses := make([]model.TagResponse, 0, len(*tags))
 
 	for _, tag := range *tags {
-		responses = append(responses, model.TagResponse{
-			ID:        tag.ID,
-			Name:      tag.Name,
-			UserID:    tag.UserID,
-			CreatedAt: tag.CreatedAt,
-			UpdatedAt: tag.UpdatedAt,
-			//Persons:   tag.Persons,
-			User: UserToResponse(tag.User),
-		})
+		responses = append(responses, *TagToResponse(&tag))
 	}
 
 	return &responses
 }
+
+// tagResponses converts loaded tags, nil when none were loaded
+func tagResponses(tags []entity.Tag) []model.TagResponse {
+	if len(tags) == 0 {
+		return nil
+	}
+	return *TagsToResponses(&tags)
+}
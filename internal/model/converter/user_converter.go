@@ -11,13 +11,15 @@ func UserToResponse(user *entity.User) *model.UserResponse {
 	}
 
 	return &model.UserResponse{
-		ID:         user.ID,
-		Email:      user.Email,
-		Name:       user.Name,
-		Avatar:     user.Avatar,
-		VerifiedAt: user.VerifiedAt,
-		CreatedAt:  user.CreatedAt,
-		UpdatedAt:  user.UpdatedAt,
+		ID:               user.ID,
+		Email:            user.Email,
+		PendingEmail:     user.PendingEmail,
+		Name:             user.Name,
+		Avatar:           user.Avatar,
+		VerifiedAt:       user.VerifiedAt,
+		TwoFactorEnabled: user.TotpEnabledAt != 0,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
 	}
 }
 
@@ -27,6 +29,7 @@ func UserToTokenResponse(user *entity.User) *model.UserResponse {
 	}
 
 	return &model.UserResponse{
-		Token: user.Token,
+		Token:        user.Token,
+		RefreshToken: user.RefreshToken,
 	}
 }
@@ -17,8 +17,8 @@ type ImportantDateResponse struct {
 type CreateImportantDateRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Date     string `json:"date" validate:"required"`
-	PersonID string `json:"person_id"`
-	UserID   string `json:"-"`
+	PersonID string `json:"person_id" validate:"required"`
+	UserID   string `json:"-" validate:"required"`
 }
 
 type UpdateImportantDateRequest struct {
@@ -26,14 +26,14 @@ type UpdateImportantDateRequest struct {
 	Date     string `json:"date"`
 	Name     string `json:"name"`
 	PersonID string `json:"person_id"`
-	UserID   string `json:"-"`
+	UserID   string `json:"-" validate:"required"`
 }
 type GetImportantDateRequest struct {
 	Query
-	UserID string `json:"-"`
+	UserID string `json:"-" validate:"required"`
 }
 
 type DeleteImportantDateRequest struct {
 	ID     string `json:"id" validate:"required"`
-	UserID string `json:"-"`
+	UserID string `json:"-" validate:"required"`
 }
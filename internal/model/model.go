@@ -16,8 +16,10 @@ type PageResponse[T any] struct {
 }
 
 type PaginationMeta struct {
-	Total      int64 `json:"total"`
-	Page       int   `json:"page"`
-	PageSize   int   `json:"page_size"`
-	TotalPages int64 `json:"total_pages"`
+	Total      *int64 `json:"total,omitempty"`
+	Page       *int   `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	TotalPages *int64 `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
@@ -0,0 +1,16 @@
+package model
+
+type OidcAuthorizeRequest struct {
+	Provider string `json:"-" validate:"required"`
+}
+
+type OidcAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+type OidcCallbackRequest struct {
+	Provider string `json:"-" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+	State    string `json:"state" validate:"required"`
+}
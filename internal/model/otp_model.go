@@ -4,6 +4,7 @@ type OtpResponse struct {
 	ID        string `json:"id,omitempty"`
 	UserID    string `json:"user_id,omitempty"`
 	Token     string `json:"token,omitempty"`
+	Purpose   string `json:"purpose,omitempty"`
 	VerfiedAt int64  `json:"verified_at,omitempty"`
 	ExpiresAt int64  `json:"expires_at,omitempty"`
 	CreatedAt int64  `json:"created_at,omitempty"`
@@ -14,6 +15,17 @@ type VerifyOtpRequest struct {
 	Token string `json:"token" validate:"required"`
 }
 
+type VerifyEmailRequest struct {
+	UserID string `json:"-" validate:"required"`
+	Otp    string `json:"otp" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	UserID string `json:"-" validate:"required"`
+}
+
+// Only purposes that start from a signed-out state can be requested by email
 type CreateOtpRequest struct {
-	Email string `json:"email" validate:"required"`
+	Email   string `json:"email" validate:"required"`
+	Purpose string `json:"purpose" validate:"required,oneof=email_verification password_reset"`
 }
@@ -0,0 +1,31 @@
+package model
+
+type PersonFacetsResponse struct {
+	Total            int64                `json:"total"`
+	WithPhone        *int64               `json:"with_phone,omitempty"`
+	WithUpcomingDate *int64               `json:"with_upcoming_date,omitempty"`
+	UpcomingDays     int                  `json:"upcoming_days"`
+	Tags             []FacetCountResponse `json:"tags"`
+	Relationships    []FacetCountResponse `json:"relationships"`
+	CreatedMonths    []MonthCountResponse `json:"created_months"`
+}
+
+type FacetCountResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type MonthCountResponse struct {
+	Month string `json:"month"` // YYYY-MM
+	Count int64  `json:"count"`
+}
+
+// GetPersonFacetsRequest counts the persons Query matches; its sorting and
+// paging are ignored.
+type GetPersonFacetsRequest struct {
+	Query
+	UserID       string   `json:"-" validate:"required"`
+	UpcomingDays int      `json:"upcoming_days" validate:"omitempty,min=1,max=365"`
+	Omit         []string `json:"-"` // person relations the caller may not read
+}
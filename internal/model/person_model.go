@@ -3,27 +3,33 @@ package model
 import "time"
 
 type PersonResponse struct {
-	ID          string    `json:"id,omitempty"`
-	FirstName   string    `json:"first_name,omitempty"`
-	LastName    string    `json:"last_name,omitempty"`
-	Nickname    string    `json:"nickname,omitempty"`
-	Avatar      string    `json:"avatar,omitempty"`
-	Description string    `json:"description,omitempty"`
-	UserID      string    `json:"user_id,omitempty"`
-	CreatedAt   time.Time `json:"created_at,omitempty"`
-	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+	ID            string    `json:"id,omitempty"`
+	FirstName     string    `json:"first_name,omitempty"`
+	LastName      string    `json:"last_name,omitempty"`
+	Nickname      string    `json:"nickname,omitempty"`
+	Avatar        string    `json:"avatar,omitempty"`
+	Description   string    `json:"description,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+	AddressBookID string    `json:"address_book_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at,omitempty"`
 
-	User *UserResponse `json:"user,omitempty"`
+	Tags           []TagResponse           `json:"tags,omitempty"`
+	Relationships  []RelationshipResponse  `json:"relationships,omitempty"`
+	Phones         []PhoneResponse         `json:"phones,omitempty"`
+	ImportantDates []ImportantDateResponse `json:"important_dates,omitempty"`
+	User           *UserResponse           `json:"user,omitempty"`
 }
 
 type CreatePersonRequest struct {
-	FirstName   string   `json:"first_name" validate:"required"`
-	LastName    string   `json:"last_name,omitempty"`
-	Nickname    string   `json:"nickname,omitempty"`
-	Avatar      string   `json:"avatar,omitempty"`
-	Description string   `json:"description,omitempty"`
-	UserID      string   `json:"-"`
-	TagIDs      []string `json:"tag_ids,omitempty"`
+	FirstName     string   `json:"first_name" validate:"required"`
+	LastName      string   `json:"last_name,omitempty"`
+	Nickname      string   `json:"nickname,omitempty"`
+	Avatar        string   `json:"avatar,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	UserID        string   `json:"-" validate:"required"`
+	AddressBookID string   `json:"address_book_id,omitempty"`
+	TagIDs        []string `json:"tag_ids,omitempty"`
 }
 
 type UpdatePersonRequest struct {
@@ -33,14 +39,34 @@ type UpdatePersonRequest struct {
 	Nickname    string   `json:"nickname,omitempty"`
 	Avatar      string   `json:"avatar,omitempty"`
 	Description string   `json:"description,omitempty"`
-	UserID      string   `json:"-"`
+	UserID      string   `json:"-" validate:"required"`
 	TagIDs      []string `json:"tag_ids,omitempty"`
 }
 type GetPersonRequest struct {
 	Query
-	UserID string `json:"-"`
+	UserID string   `json:"-" validate:"required"`
+	Omit   []string `json:"-"` // person relations the caller may not read
+}
+
+// SimilarPersonResponse is a person whose name resembles the one searched for
+type SimilarPersonResponse struct {
+	PersonResponse
+	Similarity float64 `json:"similarity"`
+}
+
+type GetSimilarPersonRequest struct {
+	UserID string `json:"-" validate:"required"`
+	Name   string `query:"name" validate:"required,max=100"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+type GetPersonByIDRequest struct {
+	ID     string   `json:"-" validate:"required"`
+	UserID string   `json:"-" validate:"required"`
+	Omit   []string `json:"-"` // person relations the caller may not read
 }
 
 type DeletePersonRequest struct {
-	ID string `json:"id" validate:"required"`
+	ID     string `json:"id" validate:"required"`
+	UserID string `json:"-" validate:"required"`
 }
@@ -17,8 +17,8 @@ type PhoneResponse struct {
 type CreatePhoneRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Number   string `json:"number" validate:"required"`
-	PersonID string `json:"person_id"`
-	UserID   string `json:"-"`
+	PersonID string `json:"person_id" validate:"required"`
+	UserID   string `json:"-" validate:"required"`
 }
 
 type UpdatePhoneRequest struct {
@@ -26,14 +26,14 @@ type UpdatePhoneRequest struct {
 	Number   string `json:"number"`
 	Name     string `json:"name"`
 	PersonID string `json:"person_id"`
-	UserID   string `json:"-"`
+	UserID   string `json:"-" validate:"required"`
 }
 type GetPhoneRequest struct {
 	Query
-	UserID string `json:"-"`
+	UserID string `json:"-" validate:"required"`
 }
 
 type DeletePhoneRequest struct {
 	ID     string `json:"id" validate:"required"`
-	UserID string `json:"-"`
+	UserID string `json:"-" validate:"required"`
 }
@@ -5,13 +5,105 @@ type DateRange struct {
 	To   string `json:"to"`
 }
 
+type FilterOperator string
+
+const (
+	FilterEq         FilterOperator = "eq"
+	FilterNe         FilterOperator = "ne"
+	FilterIn         FilterOperator = "in"
+	FilterNotIn      FilterOperator = "not_in"
+	FilterGt         FilterOperator = "gt"
+	FilterGte        FilterOperator = "gte"
+	FilterLt         FilterOperator = "lt"
+	FilterLte        FilterOperator = "lte"
+	FilterIsNull     FilterOperator = "is_null"     // value true or false; empty text counts as null
+	FilterStartsWith FilterOperator = "starts_with" // case sensitive prefix
+	FilterContains   FilterOperator = "contains"    // case insensitive substring
+	FilterMonth      FilterOperator = "month"       // month 1-12 of a date field, any year
+)
+
+// Filter is either a single condition on Field, or a group whose And or Or
+// members are combined. Groups nest, e.g.
+//
+//	{"and": [
+//	  {"field": "last_name", "op": "is_null", "value": true},
+//	  {"field": "created_at", "op": "gte", "value": "2025-11-01"},
+//	  {"field": "nickname", "op": "starts_with", "value": "J"}
+//	]}
+type Filter struct {
+	Field string         `json:"field,omitempty"`
+	Op    FilterOperator `json:"op,omitempty"`
+	Value any            `json:"value,omitempty"`
+	And   []Filter       `json:"and,omitempty"`
+	Or    []Filter       `json:"or,omitempty"`
+}
+
+type RelationMode string
+
+const (
+	RelationAny  RelationMode = "any"  // some related row meets one of the conditions, the default
+	RelationAll  RelationMode = "all"  // every condition is met by some related row
+	RelationNone RelationMode = "none" // no related row meets any of the conditions
+)
+
+// RelationFilter matches rows by their related rows, e.g. persons tagged both
+// Work and Family:
+//
+//	{"relation": "tags", "mode": "all", "match": [
+//	  {"field": "name", "op": "eq", "value": "Work"},
+//	  {"field": "name", "op": "eq", "value": "Family"}
+//	]}
+//
+// Without conditions any and none ask whether there is a related row at all.
+type RelationFilter struct {
+	Relation string       `json:"relation"`
+	Mode     RelationMode `json:"mode"`
+	Match    []Filter     `json:"match"`
+}
+
+// SearchMode decides how the Search map matches text fields
+type SearchMode string
+
+const (
+	SearchModeLike  SearchMode = "like"  // substring match, the default
+	SearchModeFuzzy SearchMode = "fuzzy" // trigram similarity, best matches first
+)
+
+// SortKey orders rows by Field, ascending unless Order is desc. Nulls, and
+// empty text, come last ascending and first descending unless Nulls is first
+// or last.
+type SortKey struct {
+	Field string `json:"field"`
+	Order string `json:"order"`
+	Nulls string `json:"nulls"`
+}
+
 type Query struct {
-	Search     map[string]string    `json:"search"` // field → value
-	Or         bool                 `json:"or"`     // optional: OR instead of AND
+	Search     map[string]string    `json:"search"` // field → value, all must match
+	SearchMode SearchMode           `json:"search_mode"`
+	Filter     *Filter              `json:"filter"`
+	Relations  []RelationFilter     `json:"relations"` // all must match
+	Sort       []SortKey            `json:"sort"`      // in order of precedence, replaces SortBy and Order
+	Collation  string               `json:"collation"` // for text sort keys, e.g. "de-DE-x-icu"
 	SortBy     string               `json:"sort_by"`
 	Order      string               `json:"order"`
 	Limit      int                  `json:"limit"`
 	Offset     int                  `json:"offset"`
+	Cursor     string               `json:"cursor"`     // next or prev cursor of a previous page, replaces Offset
+	SkipTotal  bool                 `json:"skip_total"` // leave out the total count
 	DateRanges map[string]DateRange `json:"date_ranges"`
 	Preload    []string             `json:"preload"`
+
+	// Fields picks the columns returned for each row, all when empty.
+	// IncludeFields does the same for a preloaded relation, keyed by its
+	// Preload path, e.g. "Relationships.Persons": ["first_name", "avatar"].
+	Fields        []string            `json:"fields"`
+	IncludeFields map[string][]string `json:"include_fields"`
+}
+
+// PageInfo describes the page a list query returned
+type PageInfo struct {
+	Total      *int64 // nil when the query skipped the count
+	NextCursor string
+	PrevCursor string
 }
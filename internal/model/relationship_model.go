@@ -5,21 +5,23 @@ import (
 )
 
 type RelationshipResponse struct {
-	ID        string            `json:"id,omitempty"`
-	Name      string            `json:"name,omitempty"`
-	Color     string            `json:"color,omitempty"`
-	UserID    string            `json:"user_id,omitempty"`
-	CreatedAt time.Time         `json:"created_at,omitempty"`
-	UpdatedAt time.Time         `json:"updated_at,omitempty"`
-	Persons   *[]PersonResponse `json:"persons,omitempty"`
-	User      *UserResponse     `json:"user,omitempty"`
+	ID            string            `json:"id,omitempty"`
+	Name          string            `json:"name,omitempty"`
+	Color         string            `json:"color,omitempty"`
+	UserID        string            `json:"user_id,omitempty"`
+	AddressBookID string            `json:"address_book_id,omitempty"`
+	CreatedAt     time.Time         `json:"created_at,omitempty"`
+	UpdatedAt     time.Time         `json:"updated_at,omitempty"`
+	Persons       *[]PersonResponse `json:"persons,omitempty"`
+	User          *UserResponse     `json:"user,omitempty"`
 }
 
 type CreateRelationshipRequest struct {
-	Name      string   `json:"name" validate:"required"`
-	Color     string   `json:"color"`
-	PersonIDs []string `json:"person_ids"`
-	UserID    string   `json:"-"`
+	Name          string   `json:"name" validate:"required"`
+	Color         string   `json:"color"`
+	PersonIDs     []string `json:"person_ids"`
+	UserID        string   `json:"-" validate:"required"`
+	AddressBookID string   `json:"address_book_id"`
 }
 
 type UpdateRelationshipRequest struct {
@@ -27,14 +29,14 @@ type UpdateRelationshipRequest struct {
 	Name      string   `json:"name"`
 	Color     string   `json:"color"`
 	PersonIDs []string `json:"person_ids"`
-	UserID    string   `json:"-"`
+	UserID    string   `json:"-" validate:"required"`
 }
 type GetRelationshipRequest struct {
 	Query
-	UserID string `json:"-"`
+	UserID string `json:"-" validate:"required"`
 }
 
 type DeleteRelationshipRequest struct {
 	ID     string `json:"id" validate:"required"`
-	UserID string `json:"-"`
+	UserID string `json:"-" validate:"required"`
 }
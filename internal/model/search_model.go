@@ -0,0 +1,17 @@
+package model
+
+type SearchHitResponse struct {
+	Type     string  `json:"type"`
+	ID       string  `json:"id"`
+	PersonID string  `json:"person_id,omitempty"`
+	Title    string  `json:"title"`
+	Snippet  string  `json:"snippet"`
+	Rank     float64 `json:"rank"`
+}
+
+type SearchRequest struct {
+	UserID string   `json:"-" validate:"required"`
+	Types  []string `json:"-" query:"-" validate:"required,min=1"` // hit types the caller's scopes allow
+	Query  string   `query:"q" validate:"required,max=200"`
+	Limit  int      `query:"limit" validate:"omitempty,min=1,max=100"`
+}
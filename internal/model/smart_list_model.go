@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+type SmartListResponse struct {
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Query     Query     `json:"query"`
+	Pinned    bool      `json:"pinned"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// CreateSmartListRequest saves Query, a person list query with its filters,
+// sort and includes, under Name.
+type CreateSmartListRequest struct {
+	UserID   string   `json:"-" validate:"required"`
+	Name     string   `json:"name" validate:"required,max=100"`
+	Query    Query    `json:"query"`
+	Pinned   bool     `json:"pinned"`
+	Position int      `json:"position" validate:"min=0"`
+	Omit     []string `json:"-"` // person relations the caller may not read
+}
+
+type GetSmartListRequest struct {
+	UserID string `json:"-" validate:"required"`
+	Pinned bool   `query:"pinned"` // only the lists pinned to the dashboard
+}
+
+type GetSmartListByIDRequest struct {
+	ID     string `json:"-" validate:"required"`
+	UserID string `json:"-" validate:"required"`
+}
+
+// UpdateSmartListRequest changes the fields that are set
+type UpdateSmartListRequest struct {
+	ID       string   `json:"-" validate:"required"`
+	UserID   string   `json:"-" validate:"required"`
+	Name     string   `json:"name,omitempty" validate:"max=100"`
+	Query    *Query   `json:"query,omitempty"`
+	Pinned   *bool    `json:"pinned,omitempty"`
+	Position *int     `json:"position,omitempty" validate:"omitempty,min=0"`
+	Omit     []string `json:"-"` // person relations the caller may not read
+}
+
+type DeleteSmartListRequest struct {
+	ID     string `json:"-" validate:"required"`
+	UserID string `json:"-" validate:"required"`
+}
+
+// EvaluateSmartListRequest runs a saved list. The paging given here replaces
+// the saved one.
+type EvaluateSmartListRequest struct {
+	ID        string   `json:"-" validate:"required"`
+	UserID    string   `json:"-" validate:"required"`
+	Limit     int      `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset    int      `query:"offset" validate:"min=0"`
+	Cursor    string   `query:"cursor"`
+	SkipTotal bool     `query:"skip_total"`
+	Omit      []string `json:"-" query:"-"` // person relations the caller may not read
+}
@@ -5,33 +5,35 @@ import (
 )
 
 type TagResponse struct {
-	ID        string           `json:"id,omitempty"`
-	Name      string           `json:"name,omitempty"`
-	UserID    string           `json:"user_id,omitempty"`
-	CreatedAt time.Time        `json:"created_at,omitempty"`
-	UpdatedAt time.Time        `json:"updated_at,omitempty"`
-	Persons   []PersonResponse `json:"persons,omitempty"`
-	User      *UserResponse    `json:"user,omitempty"`
+	ID            string           `json:"id,omitempty"`
+	Name          string           `json:"name,omitempty"`
+	UserID        string           `json:"user_id,omitempty"`
+	AddressBookID string           `json:"address_book_id,omitempty"`
+	CreatedAt     time.Time        `json:"created_at,omitempty"`
+	UpdatedAt     time.Time        `json:"updated_at,omitempty"`
+	Persons       []PersonResponse `json:"persons,omitempty"`
+	User          *UserResponse    `json:"user,omitempty"`
 }
 
 type CreateTagRequest struct {
-	Name      string   `json:"name" validate:"required"`
-	PersonIDs []string `json:"person_ids"`
-	UserID    string   `json:"-"`
+	Name          string   `json:"name" validate:"required"`
+	PersonIDs     []string `json:"person_ids"`
+	UserID        string   `json:"-" validate:"required"`
+	AddressBookID string   `json:"address_book_id"`
 }
 
 type UpdateTagRequest struct {
 	ID        string   `json:"id" validate:"required"`
 	Name      string   `json:"name"`
 	PersonIDs []string `json:"person_ids"`
-	UserID    string   `json:"-"`
+	UserID    string   `json:"-" validate:"required"`
 }
 type GetTagRequest struct {
 	Query
-	UserID string `json:"-"`
+	UserID string `json:"-" validate:"required"`
 }
 
 type DeleteTagRequest struct {
 	ID     string `json:"id" validate:"required"`
-	UserID string `json:"-"`
+	UserID string `json:"-" validate:"required"`
 }
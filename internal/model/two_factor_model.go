@@ -0,0 +1,40 @@
+package model
+
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type EnrollTwoFactorRequest struct {
+	ID string `json:"-" validate:"required"`
+}
+
+type ConfirmTwoFactorRequest struct {
+	ID   string `json:"-" validate:"required"`
+	Code string `json:"code" validate:"required"`
+	IP   string `json:"-"`
+}
+
+// Code accepts either a TOTP code or an unused recovery code
+type DisableTwoFactorRequest struct {
+	ID   string `json:"-" validate:"required"`
+	Code string `json:"code" validate:"required"`
+	IP   string `json:"-"`
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	ID   string `json:"-" validate:"required"`
+	Code string `json:"code" validate:"required"`
+	IP   string `json:"-"`
+}
+
+// Code accepts either a TOTP code or an unused recovery code
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+	IP             string `json:"-"`
+}
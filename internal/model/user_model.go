@@ -1,14 +1,19 @@
 package model
 
 type UserResponse struct {
-	ID         string `json:"id,omitempty"`
-	Email      string `json:"email,omitempty"`
-	Name       string `json:"name,omitempty"`
-	Avatar     string `json:"avatar,omitempty"`
-	Token      string `json:"token,omitempty"`
-	VerifiedAt int64  `json:"verified_at,omitempty"`
-	CreatedAt  int64  `json:"created_at,omitempty"`
-	UpdatedAt  int64  `json:"updated_at,omitempty"`
+	ID                string `json:"id,omitempty"`
+	Email             string `json:"email,omitempty"`
+	PendingEmail      string `json:"pending_email,omitempty"`
+	Name              string `json:"name,omitempty"`
+	Avatar            string `json:"avatar,omitempty"`
+	Token             string `json:"token,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	TwoFactorEnabled  bool   `json:"two_factor_enabled,omitempty"`
+	VerifiedAt        int64  `json:"verified_at,omitempty"`
+	CreatedAt         int64  `json:"created_at,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
 }
 
 type VerifyUserRequest struct {
@@ -23,12 +28,17 @@ type RegisterUserRequest struct {
 
 type UpdateUserRequest struct {
 	ID         string `json:"-"`
-	Email      string `json:"email,omitempty"`
+	Email      string `json:"email,omitempty" validate:"omitempty,email"`
 	Name       string `json:"name,omitempty"`
 	Avatar     string `json:"avatar,omitempty"`
 	Token      string `json:"token,omitempty"`
 	VerifiedAt string `json:"verified_at,omitempty"`
 }
+type ConfirmEmailChangeRequest struct {
+	ID  string `json:"-" validate:"required"`
+	Otp string `json:"otp" validate:"required"`
+}
+
 type UpdateUserPasswordRequest struct {
 	ID       string `json:"-"`
 	Token    string `json:"token" validate:"required"`
@@ -39,10 +49,16 @@ type LoginUserRequest struct {
 	ID       string `json:"-"`
 	Email    string `json:"email,omitempty"`
 	Password string `json:"password" validate:"required"`
+	IP       string `json:"-"`
+}
+
+type RefreshUserRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 type LogoutUserRequest struct {
-	ID string `json:"-"`
+	ID        string `json:"-"`
+	SessionID string `json:"-"`
 }
 
 type GetUserRequest struct {
@@ -2,6 +2,8 @@ package auth
 
 import (
 	"codename-rl/internal/entity"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,29 +14,68 @@ type JwtClaims struct {
 	jwt.RegisteredClaims
 }
 
+// JwtService signs with the newest key that holds a private key and verifies
+// with any configured key, selected by the kid header. Without keys it falls
+// back to HS256 with the shared secret.
 type JwtService struct {
-	secret []byte
+	secret  []byte
+	keys    map[string]*SigningKey
+	ordered []*SigningKey
+	signing *SigningKey
 }
 
-func NewJwtService(secret string) *JwtService {
-	return &JwtService{secret: []byte(secret)}
+// ErrNoSigningKey is returned when keys are configured but none of them can
+// sign, so no issued token could ever be verified.
+var ErrNoSigningKey = errors.New("no configured jwt key holds a private key")
+
+// NewJwtService expects keys ordered from oldest to newest
+func NewJwtService(secret string, keys ...*SigningKey) (*JwtService, error) {
+	service := &JwtService{
+		secret:  []byte(secret),
+		keys:    make(map[string]*SigningKey, len(keys)),
+		ordered: keys,
+	}
+
+	for _, key := range keys {
+		service.keys[key.ID] = key
+		if key.PrivateKey != nil {
+			service.signing = key
+		}
+	}
+
+	if len(keys) > 0 && service.signing == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	return service, nil
 }
 
 func (r *JwtService) GenerateToken(user *entity.User, expiresIn time.Duration) (string, error) {
+	return r.GenerateSessionToken(user, "", expiresIn)
+}
+
+// GenerateSessionToken signs a token carrying sessionID as its jti claim
+func (r *JwtService) GenerateSessionToken(user *entity.User, sessionID string, expiresIn time.Duration) (string, error) {
 	expirationTime := time.Now().Add(expiresIn)
 
 	claims := &JwtClaims{
 		ID: user.ID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "codename-rl",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if r.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(r.secret)
+	}
+
+	token := jwt.NewWithClaims(r.signing.Method, claims)
+	token.Header["kid"] = r.signing.ID
 
-	tokenString, err := token.SignedString(r.secret)
+	tokenString, err := token.SignedString(r.signing.PrivateKey)
 	if err != nil {
 		return "", err
 	}
@@ -45,9 +86,7 @@ func (r *JwtService) GenerateToken(user *entity.User, expiresIn time.Duration) (
 func (r *JwtService) ValidateToken(tokenString string) (*JwtClaims, error) {
 	claims := &JwtClaims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return r.secret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, r.keyFunc)
 
 	if err != nil || !token.Valid {
 		return nil, err
@@ -55,3 +94,33 @@ func (r *JwtService) ValidateToken(tokenString string) (*JwtClaims, error) {
 
 	return claims, nil
 }
+
+// JWKS returns the public keys that tokens may be verified with
+func (r *JwtService) JWKS() *JWKSet {
+	set := &JWKSet{Keys: make([]JWK, 0, len(r.ordered))}
+	for _, key := range r.ordered {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	return set
+}
+
+func (r *JwtService) keyFunc(token *jwt.Token) (interface{}, error) {
+	if len(r.keys) == 0 {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return r.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), kid)
+	}
+
+	return key.PublicKey, nil
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyConfig describes one entry of jwt.keys in config.json.
+// A key without a private key file only verifies tokens, which is how a
+// retired signing key is kept around until its tokens have expired.
+type KeyConfig struct {
+	ID             string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// JWK is a single public key in RFC 7517 form
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func LoadSigningKey(cfg KeyConfig) (*SigningKey, error) {
+	if cfg.ID == "" {
+		return nil, errors.New("jwt key is missing kid")
+	}
+
+	key := &SigningKey{ID: cfg.ID}
+	switch cfg.Algorithm {
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+	case "EdDSA":
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("jwt key %s: unsupported algorithm %q", cfg.ID, cfg.Algorithm)
+	}
+
+	if cfg.PrivateKeyFile != "" {
+		block, err := readPEM(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", cfg.ID, err)
+		}
+
+		var private any
+		if private, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+			if private, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+				return nil, fmt.Errorf("jwt key %s: failed to parse private key: %w", cfg.ID, err)
+			}
+		}
+
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("jwt key %s: private key cannot sign", cfg.ID)
+		}
+		key.PrivateKey = signer
+		key.PublicKey = signer.Public()
+	} else if cfg.PublicKeyFile != "" {
+		block, err := readPEM(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", cfg.ID, err)
+		}
+
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: failed to parse public key: %w", cfg.ID, err)
+		}
+		key.PublicKey = public
+	} else {
+		return nil, fmt.Errorf("jwt key %s: private_key_file or public_key_file is required", cfg.ID)
+	}
+
+	switch key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if key.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("jwt key %s: RSA key used with %s", cfg.ID, cfg.Algorithm)
+		}
+	case ed25519.PublicKey:
+		if key.Method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("jwt key %s: Ed25519 key used with %s", cfg.ID, cfg.Algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("jwt key %s: unsupported key type %T", cfg.ID, key.PublicKey)
+	}
+
+	return key, nil
+}
+
+// JWK returns the public half of the key
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{Kid: k.ID, Alg: k.Method.Alg(), Use: "sig"}
+
+	switch public := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+
+	return jwk
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+
+	return block, nil
+}
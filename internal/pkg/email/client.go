@@ -1,7 +1,10 @@
 package email
 
 import (
+	"bytes"
 	"fmt"
+	"text/template"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
@@ -18,17 +21,85 @@ type SMTPConfig struct {
 	From     string
 }
 
+// Template is the subject and plain text body of one kind of OTP mail.
+// The body receives .Otp and .Minutes.
+type Template struct {
+	Subject string
+	Body    *template.Template
+}
+
+var otpTemplates = map[string]Template{
+	"email_verification": {
+		Subject: "Verify your email address",
+		Body: template.Must(template.New("email_verification").Parse(
+			"Welcome! Your email verification code is: {{.Otp}}\n\n" +
+				"This code will expire in {{.Minutes}} minutes.")),
+	},
+	"password_reset": {
+		Subject: "Reset your password",
+		Body: template.Must(template.New("password_reset").Parse(
+			"Your password reset code is: {{.Otp}}\n\n" +
+				"This code will expire in {{.Minutes}} minutes. " +
+				"If you did not ask to reset your password, you can ignore this email.")),
+	},
+	"email_change": {
+		Subject: "Confirm your new email address",
+		Body: template.Must(template.New("email_change").Parse(
+			"Your code to confirm this new email address is: {{.Otp}}\n\n" +
+				"This code will expire in {{.Minutes}} minutes.")),
+	},
+	"account_deletion": {
+		Subject: "Confirm account deletion",
+		Body: template.Must(template.New("account_deletion").Parse(
+			"Your code to confirm the deletion of your account is: {{.Otp}}\n\n" +
+				"This code will expire in {{.Minutes}} minutes. " +
+				"If you did not ask to delete your account, change your password now.")),
+	},
+}
+
 func NewClient(cfg *SMTPConfig) *Client {
 	return &Client{cfg: *cfg}
 }
 
-func (c *Client) SendOTP(to, otp string) error {
+// SendOTP mails otp using the template registered for purpose
+func (c *Client) SendOTP(to, otp, purpose string, ttl time.Duration) error {
+	tmpl, ok := otpTemplates[purpose]
+	if !ok {
+		return fmt.Errorf("no email template for OTP purpose %q", purpose)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Body.Execute(&body, map[string]any{
+		"Otp":     otp,
+		"Minutes": int(ttl.Minutes()),
+	}); err != nil {
+		return err
+	}
+
+	return c.send(to, tmpl.Subject, body.String())
+}
+
+// SendEmailChanged tells the previous address that the login email was changed
+func (c *Client) SendEmailChanged(to, newEmail string) error {
+	body := fmt.Sprintf("The email address of your account was changed to %s.\n\n"+
+		"If you did not make this change, reset your password and contact support.", newEmail)
+	return c.send(to, "Your email address was changed", body)
+}
+
+// SendAddressBookInvitation mails the token that accepts or declines an
+// invitation to join an address book
+func (c *Client) SendAddressBookInvitation(to, inviter, book, role, token string, ttl time.Duration) error {
+	body := fmt.Sprintf("%s invited you to join the address book %q as %s.\n\n"+
+		"Sign in with this email address and use the following code to accept or decline: %s\n\n"+
+		"This invitation will expire in %d days.", inviter, book, role, token, int(ttl.Hours()/24))
+	return c.send(to, "You were invited to an address book", body)
+}
+
+func (c *Client) send(to, subject, body string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", c.cfg.From)
 	m.SetHeader("To", to)
-	m.SetHeader("Subject", "Your OTP Code")
-
-	body := fmt.Sprintf("Your OTP code is: %s\n\nThis code will expire shortly.", otp)
+	m.SetHeader("Subject", subject)
 	m.SetBody("text/plain", body)
 
 	d := gomail.NewDialer(c.cfg.Host, c.cfg.Port, c.cfg.Email, c.cfg.Password)
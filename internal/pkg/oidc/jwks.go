@@ -0,0 +1,79 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// publicKeys decodes the RSA and EC signing keys of the set by kid.
+// Encryption keys and unsupported key types are skipped.
+func (s *jwkSet) publicKeys() (map[string]interface{}, error) {
+	keys := make(map[string]interface{}, len(s.Keys))
+	for _, key := range s.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch key.Kty {
+		case "RSA":
+			n, err := decodeBigInt(key.N)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key.Kid, err)
+			}
+			e, err := decodeBigInt(key.E)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key.Kid, err)
+			}
+			keys[key.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch key.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, err := decodeBigInt(key.X)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key.Kid, err)
+			}
+			y, err := decodeBigInt(key.Y)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key.Kid, err)
+			}
+			keys[key.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	}
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,31 @@
+package oidc
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Registry holds the configured providers by name
+type Registry struct {
+	providers map[string]*Provider
+}
+
+func NewRegistry(configs []ProviderConfig, client *http.Client) (*Registry, error) {
+	registry := &Registry{providers: make(map[string]*Provider, len(configs))}
+	for _, cfg := range configs {
+		provider, err := NewProvider(cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := registry.providers[cfg.Name]; ok {
+			return nil, fmt.Errorf("duplicate oidc provider %q", cfg.Name)
+		}
+		registry.providers[cfg.Name] = provider
+	}
+	return registry, nil
+}
+
+func (r *Registry) Get(name string) (*Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AccessTokenRepository struct {
+	Repository[entity.AccessToken]
+	Log *logrus.Logger
+}
+
+func NewAccessTokenRepository(log *logrus.Logger) *AccessTokenRepository {
+	return &AccessTokenRepository{
+		Log: log,
+	}
+}
+
+func (r *AccessTokenRepository) FindByHash(ctx context.Context, db *gorm.DB, token *entity.AccessToken, hash string) error {
+	return db.WithContext(ctx).Where("token_hash = ?", hash).Take(token).Error
+}
+
+func (r *AccessTokenRepository) FindByIDAndUserID(ctx context.Context, db *gorm.DB, token *entity.AccessToken, id string, userID string) error {
+	return db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Take(token).Error
+}
+
+func (r *AccessTokenRepository) FindAllByUserID(ctx context.Context, db *gorm.DB, tokens *[]entity.AccessToken, userID string) error {
+	return db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(tokens).Error
+}
+
+// DeleteByUserID revokes every token of userID
+func (r *AccessTokenRepository) DeleteByUserID(ctx context.Context, db *gorm.DB, userID string) error {
+	return db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.AccessToken{}).Error
+}
+
+func (r *AccessTokenRepository) TouchLastUsed(ctx context.Context, db *gorm.DB, id string, usedAt int64) error {
+	return db.WithContext(ctx).
+		Model(&entity.AccessToken{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).
+		Error
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AddressBookInvitationRepository struct {
+	Repository[entity.AddressBookInvitation]
+	Log *logrus.Logger
+}
+
+func NewAddressBookInvitationRepository(log *logrus.Logger) *AddressBookInvitationRepository {
+	return &AddressBookInvitationRepository{
+		Log: log,
+	}
+}
+
+func (r *AddressBookInvitationRepository) FindByHash(ctx context.Context, db *gorm.DB, invitation *entity.AddressBookInvitation, hash string) error {
+	return db.WithContext(ctx).
+		Preload("AddressBook").
+		Where("token_hash = ?", hash).
+		Take(invitation).Error
+}
+
+// FindPendingByEmail lists the unexpired invitations sent to email
+func (r *AddressBookInvitationRepository) FindPendingByEmail(ctx context.Context, db *gorm.DB, invitations *[]entity.AddressBookInvitation, email string, now int64) error {
+	return db.WithContext(ctx).
+		Preload("AddressBook").
+		Where("email = ? AND expires_at > ?", email, now).
+		Order("created_at DESC").
+		Find(invitations).Error
+}
+
+func (r *AddressBookInvitationRepository) DeleteByBookIDAndEmail(ctx context.Context, db *gorm.DB, bookID string, email string) error {
+	return db.WithContext(ctx).
+		Where("address_book_id = ? AND email = ?", bookID, email).
+		Delete(&entity.AddressBookInvitation{}).Error
+}
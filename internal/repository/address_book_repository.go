@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// AddressBookRepository stores address books together with their members
+type AddressBookRepository struct {
+	Repository[entity.AddressBook]
+	Log *logrus.Logger
+}
+
+func NewAddressBookRepository(log *logrus.Logger) *AddressBookRepository {
+	return &AddressBookRepository{
+		Log: log,
+	}
+}
+
+// CreateWithOwner creates book with userID as its owner
+func (r *AddressBookRepository) CreateWithOwner(db *gorm.DB, book *entity.AddressBook, userID string) (*entity.AddressBookMember, error) {
+	if err := db.Create(book).Error; err != nil {
+		return nil, err
+	}
+
+	member := &entity.AddressBookMember{
+		ID:            uuid.New().String(),
+		AddressBookID: book.ID,
+		UserID:        userID,
+		Role:          entity.AddressBookRoleOwner,
+	}
+	if err := db.Create(member).Error; err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (r *AddressBookRepository) FindMember(ctx context.Context, db *gorm.DB, member *entity.AddressBookMember, bookID string, userID string) error {
+	return db.WithContext(ctx).
+		Where("address_book_id = ? AND user_id = ?", bookID, userID).
+		Take(member).Error
+}
+
+// FindMemberByPersonID finds userID's membership of the book that owns personID
+func (r *AddressBookRepository) FindMemberByPersonID(ctx context.Context, db *gorm.DB, member *entity.AddressBookMember, personID string, userID string) error {
+	return db.WithContext(ctx).
+		Joins("JOIN persons ON persons.address_book_id = address_book_members.address_book_id").
+		Where("persons.id = ? AND address_book_members.user_id = ?", personID, userID).
+		Take(member).Error
+}
+
+// FindDefaultMember finds the oldest book userID owns, their personal book
+func (r *AddressBookRepository) FindDefaultMember(ctx context.Context, db *gorm.DB, member *entity.AddressBookMember, userID string) error {
+	return db.WithContext(ctx).
+		Where("user_id = ? AND role = ?", userID, entity.AddressBookRoleOwner).
+		Order("created_at ASC").
+		Take(member).Error
+}
+
+func (r *AddressBookRepository) FindMembers(ctx context.Context, db *gorm.DB, members *[]entity.AddressBookMember, bookID string) error {
+	return db.WithContext(ctx).
+		Preload("User").
+		Where("address_book_id = ?", bookID).
+		Order("created_at ASC").
+		Find(members).Error
+}
+
+// FindMemberships lists the books userID belongs to, with their role in each
+func (r *AddressBookRepository) FindMemberships(ctx context.Context, db *gorm.DB, members *[]entity.AddressBookMember, userID string) error {
+	return db.WithContext(ctx).
+		Preload("AddressBook").
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(members).Error
+}
+
+func (r *AddressBookRepository) CreateMember(db *gorm.DB, member *entity.AddressBookMember) error {
+	return db.Create(member).Error
+}
+
+func (r *AddressBookRepository) UpdateMember(db *gorm.DB, member *entity.AddressBookMember) error {
+	return db.Save(member).Error
+}
+
+func (r *AddressBookRepository) DeleteMember(db *gorm.DB, member *entity.AddressBookMember) error {
+	return db.Delete(member).Error
+}
+
+// CountContents counts the persons, tags and relationships kept in bookID
+func (r *AddressBookRepository) CountContents(ctx context.Context, db *gorm.DB, bookID string) (int64, error) {
+	var total int64
+	for _, model := range []interface{}{&entity.Person{}, &entity.Tag{}, &entity.Relationship{}} {
+		var count int64
+		if err := db.WithContext(ctx).Model(model).Where("address_book_id = ?", bookID).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// Delete removes book with its members and pending invitations
+func (r *AddressBookRepository) Delete(db *gorm.DB, book *entity.AddressBook) error {
+	if err := db.Where("address_book_id = ?", book.ID).Delete(&entity.AddressBookInvitation{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("address_book_id = ?", book.ID).Delete(&entity.AddressBookMember{}).Error; err != nil {
+		return err
+	}
+	return db.Delete(book).Error
+}
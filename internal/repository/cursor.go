@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned for a cursor that does not decode or was
+// issued for a different sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the position of a row in a keyset ordered by its sort keys and
+// id. It is handed to clients as opaque base64.
+type cursor struct {
+	Sort   string `json:"s"` // signature of the sort it was issued for
+	Values []any  `json:"v,omitempty"`
+	ID     any    `json:"id"`
+	Prev   bool   `json:"p,omitempty"` // page backwards from this row
+}
+
+func (c *cursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := new(cursor)
+	if err := json.Unmarshal(raw, c); err != nil || c.ID == nil {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// cursorAt returns the cursor of row, read through the schema tx resolved
+func cursorAt(tx *gorm.DB, row reflect.Value, keys []sortKey, signature string, prev bool) *cursor {
+	c := &cursor{Sort: signature, Prev: prev}
+	s := tx.Statement.Schema
+	if s == nil || s.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	c.ID, _ = s.PrioritizedPrimaryField.ValueOf(tx.Statement.Context, row)
+	for _, k := range keys[:len(keys)-1] {
+		field := s.LookUpField(k.field)
+		if field == nil {
+			return nil
+		}
+		value, zero := field.ValueOf(tx.Statement.Context, row)
+		// Empty text sorts as null
+		if k.text && zero {
+			value = nil
+		}
+		c.Values = append(c.Values, value)
+	}
+	return c
+}
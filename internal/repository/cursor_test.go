@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor cursor
+	}{
+		{name: "id only", cursor: cursor{Sort: "id asc", ID: "p1"}},
+		{name: "values", cursor: cursor{Sort: "last_name asc,id asc", Values: []any{"Lee"}, ID: "p1"}},
+		{name: "null value", cursor: cursor{Sort: "last_name asc,id asc", Values: []any{nil}, ID: "p1"}},
+		{name: "backwards", cursor: cursor{Sort: "id desc", ID: "p1", Prev: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(tt.cursor.encode())
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.cursor) {
+				t.Fatalf("cursor = %+v, want %+v", *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "not base64", input: "!!!"},
+		{name: "not json", input: encode("id asc")},
+		{name: "wrong shape", input: encode(`{"s":1,"id":"p1"}`)},
+		{name: "missing id", input: encode(`{"s":"id asc"}`)},
+		{name: "null id", input: encode(`{"s":"id asc","id":null}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.input); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestCursorAt(t *testing.T) {
+	tx := dryRunDB(t).Model(&entity.Person{})
+	if err := tx.Statement.Parse(&entity.Person{}); err != nil {
+		t.Fatalf("parse person: %v", err)
+	}
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	keys := []sortKey{
+		{field: "last_name", text: true, nullable: true},
+		{field: "created_at", nullable: true},
+		{field: "id"},
+	}
+
+	tests := []struct {
+		name   string
+		person entity.Person
+		prev   bool
+		want   *cursor
+	}{
+		{
+			name:   "values of every key but the id",
+			person: entity.Person{ID: "p1", LastName: "Lee", CreatedAt: created},
+			want:   &cursor{Sort: "sig", Values: []any{"Lee", created}, ID: "p1"},
+		},
+		{
+			name:   "empty text is a null cursor value",
+			person: entity.Person{ID: "p2", CreatedAt: created},
+			prev:   true,
+			want:   &cursor{Sort: "sig", Values: []any{nil, created}, ID: "p2", Prev: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cursorAt(tx, reflect.ValueOf(&tt.person).Elem(), keys, "sig", tt.prev)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("cursor = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		bad := []sortKey{{field: "missing"}, {field: "id"}}
+		if got := cursorAt(tx, reflect.ValueOf(&entity.Person{ID: "p1"}).Elem(), bad, "sig", false); got != nil {
+			t.Fatalf("cursor = %+v, want nil", got)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"codename-rl/internal/model"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// fieldset holds the columns a sparse query selects, for the root rows and
+// for each preloaded relation. A nil root or a missing preload path means
+// every column.
+type fieldset struct {
+	root    []string
+	preload map[string][]string
+}
+
+// buildFieldset checks q's fields against the allowlists of the root entity
+// and of each included relation, and adds the keys GORM needs to stitch the
+// preloaded rows together.
+func buildFieldset(s *schema.Schema, value queryable, q *model.Query) (*fieldset, error) {
+	set := &fieldset{preload: map[string][]string{}}
+	if len(q.Fields) == 0 && len(q.IncludeFields) == 0 {
+		return set, nil
+	}
+
+	if len(q.Fields) > 0 {
+		fields := value.QueryFields()
+		for _, field := range q.Fields {
+			if !fields.AllowsSelect(field) {
+				return nil, &QueryFieldError{Kind: "select", Field: field}
+			}
+		}
+		set.root = withColumns(q.Fields, "id")
+		for _, key := range querySort(q) {
+			set.root = withColumns(set.root, key.Field)
+		}
+	}
+
+	for path, columns := range q.IncludeFields {
+		if !slices.ContainsFunc(q.Preload, func(include string) bool {
+			return include == path || strings.HasPrefix(include, path+".")
+		}) {
+			return nil, &QueryFieldError{Kind: "include fields", Field: path}
+		}
+		rel := relationAt(s, path)
+		if rel == nil {
+			return nil, &QueryFieldError{Kind: "include fields", Field: path}
+		}
+		related, ok := reflect.New(rel.FieldSchema.ModelType).Interface().(queryable)
+		if !ok {
+			return nil, &QueryFieldError{Kind: "include fields", Field: path}
+		}
+		for _, column := range columns {
+			if !related.QueryFields().AllowsSelect(column) {
+				return nil, &QueryFieldError{Kind: "select", Field: path + "." + column}
+			}
+		}
+
+		selected := withColumns(columns, "id")
+		// Child rows of a has-many are matched to their parent by foreign key
+		if rel.Type == schema.HasMany || rel.Type == schema.HasOne {
+			for _, ref := range rel.References {
+				if ref.OwnPrimaryKey {
+					selected = withColumns(selected, ref.ForeignKey.DBName)
+				}
+			}
+		}
+		set.preload[path] = selected
+	}
+
+	// A belongs-to relation is found through a foreign key on its parent
+	for _, path := range q.Preload {
+		rel := relationAt(s, path)
+		if rel == nil || rel.Type != schema.BelongsTo {
+			continue
+		}
+		parent := ""
+		if i := strings.LastIndexByte(path, '.'); i >= 0 {
+			parent = path[:i]
+		}
+		for _, ref := range rel.References {
+			if parent == "" && set.root != nil {
+				set.root = withColumns(set.root, ref.ForeignKey.DBName)
+			} else if columns, ok := set.preload[parent]; ok {
+				set.preload[parent] = withColumns(columns, ref.ForeignKey.DBName)
+			}
+		}
+	}
+
+	return set, nil
+}
+
+// relationAt follows a dotted Preload path from s
+func relationAt(s *schema.Schema, path string) *schema.Relationship {
+	var rel *schema.Relationship
+	for _, name := range strings.Split(path, ".") {
+		if s == nil {
+			return nil
+		}
+		rel = s.Relationships.Relations[name]
+		if rel == nil {
+			return nil
+		}
+		s = rel.FieldSchema
+	}
+	return rel
+}
+
+// withColumns returns columns plus any extra ones it lacks
+func withColumns(columns []string, extra ...string) []string {
+	result := slices.Clone(columns)
+	for _, column := range extra {
+		if column != "" && !slices.Contains(result, column) {
+			result = append(result, column)
+		}
+	}
+	return result
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"codename-rl/internal/pkg/utils"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxFilterDepth bounds how deep filter groups may nest
+	maxFilterDepth = 5
+	// maxFilterConditions bounds the number of conditions in one filter
+	maxFilterConditions = 50
+	// maxRelationFilters bounds the relation filters of one query
+	maxRelationFilters = 10
+)
+
+// FilterError reports a filter condition that cannot be applied
+type FilterError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	if e.Field == "" {
+		return "invalid filter: " + e.Reason
+	}
+	return fmt.Sprintf("invalid filter on %q: %s", e.Field, e.Reason)
+}
+
+// filterBuilder turns a model.Filter into a clause expression on table,
+// checking every field against the entity's whitelist.
+type filterBuilder struct {
+	table      string
+	fields     entity.QueryFields
+	conditions int
+}
+
+func (b *filterBuilder) build(f *model.Filter, depth int) (clause.Expression, error) {
+	if depth > maxFilterDepth {
+		return nil, &FilterError{Reason: fmt.Sprintf("groups nest deeper than %d", maxFilterDepth)}
+	}
+
+	grouped := len(f.And) > 0 || len(f.Or) > 0
+	if grouped && f.Field != "" {
+		return nil, &FilterError{Field: f.Field, Reason: "a condition cannot also be a group"}
+	}
+	if len(f.And) > 0 && len(f.Or) > 0 {
+		return nil, &FilterError{Reason: "a group has either and or or members"}
+	}
+
+	if !grouped {
+		b.conditions++
+		if b.conditions > maxFilterConditions {
+			return nil, &FilterError{Reason: fmt.Sprintf("more than %d conditions", maxFilterConditions)}
+		}
+		return b.condition(f)
+	}
+
+	members := f.And
+	if len(f.Or) > 0 {
+		members = f.Or
+	}
+
+	exprs := make([]clause.Expression, 0, len(members))
+	for i := range members {
+		expr, err := b.build(&members[i], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	if len(f.Or) > 0 {
+		return clause.Expr{SQL: "(?)", Vars: []interface{}{clause.Or(exprs...)}}, nil
+	}
+	return clause.Expr{SQL: "(?)", Vars: []interface{}{clause.And(exprs...)}}, nil
+}
+
+func (b *filterBuilder) condition(f *model.Filter) (clause.Expression, error) {
+	if f.Field == "" {
+		return nil, &FilterError{Reason: "a condition needs a field"}
+	}
+
+	// Text fields take every operator, date fields only compare
+	text := b.fields.AllowsFilter(f.Field)
+	date := b.fields.AllowsDateRange(f.Field)
+	if !text && !date {
+		return nil, &QueryFieldError{Kind: "filter", Field: f.Field}
+	}
+
+	column := clause.Column{Table: b.table, Name: f.Field}
+
+	switch f.Op {
+	case model.FilterIsNull:
+		isNull, ok := f.Value.(bool)
+		if !ok {
+			return nil, &FilterError{Field: f.Field, Reason: "is_null takes true or false"}
+		}
+		sql := "NULLIF(CAST(? AS text), '') IS NULL"
+		if !isNull {
+			sql = "NULLIF(CAST(? AS text), '') IS NOT NULL"
+		}
+		return clause.Expr{SQL: sql, Vars: []interface{}{column}}, nil
+
+	case model.FilterIn, model.FilterNotIn:
+		list, ok := f.Value.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, &FilterError{Field: f.Field, Reason: string(f.Op) + " takes a non-empty list"}
+		}
+		values := make([]interface{}, 0, len(list))
+		for _, item := range list {
+			value, err := b.scalar(f.Field, date, item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if f.Op == model.FilterNotIn {
+			return clause.Not(clause.IN{Column: column, Values: values}), nil
+		}
+		return clause.IN{Column: column, Values: values}, nil
+
+	case model.FilterMonth:
+		month, err := monthValue(f.Value)
+		if err != nil || !date {
+			return nil, &FilterError{Field: f.Field, Reason: "month takes a date field and a month from 1 to 12"}
+		}
+		return clause.Expr{SQL: "EXTRACT(MONTH FROM ?) = ?", Vars: []interface{}{column, month}}, nil
+
+	case model.FilterStartsWith, model.FilterContains:
+		value, ok := f.Value.(string)
+		if !ok || !text || date {
+			return nil, &FilterError{Field: f.Field, Reason: string(f.Op) + " takes a text field and value"}
+		}
+		if f.Op == model.FilterStartsWith {
+			return clause.Like{Column: column, Value: escapeLike(value) + "%"}, nil
+		}
+		return clause.Expr{SQL: "? ILIKE ?", Vars: []interface{}{column, "%" + escapeLike(value) + "%"}}, nil
+	}
+
+	value, err := b.scalar(f.Field, date, f.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch f.Op {
+	case model.FilterEq:
+		return clause.Eq{Column: column, Value: value}, nil
+	case model.FilterNe:
+		return clause.Neq{Column: column, Value: value}, nil
+	case model.FilterGt:
+		return clause.Gt{Column: column, Value: value}, nil
+	case model.FilterGte:
+		return clause.Gte{Column: column, Value: value}, nil
+	case model.FilterLt:
+		return clause.Lt{Column: column, Value: value}, nil
+	case model.FilterLte:
+		return clause.Lte{Column: column, Value: value}, nil
+	}
+	return nil, &FilterError{Field: f.Field, Reason: fmt.Sprintf("unknown operator %q", f.Op)}
+}
+
+// scalar checks that value is a single JSON string, parsing it as a time for
+// date fields. Every filterable column holds text or a time, so a number or
+// boolean would only fail in Postgres.
+func (b *filterBuilder) scalar(field string, date bool, value interface{}) (interface{}, error) {
+	v, ok := value.(string)
+	if !ok {
+		if date {
+			return nil, &FilterError{Field: field, Reason: "value is not a date"}
+		}
+		return nil, &FilterError{Field: field, Reason: "value must be a string"}
+	}
+	if !date {
+		return v, nil
+	}
+	t, err := utils.ParseFlexibleTime(v)
+	if err != nil {
+		return nil, &FilterError{Field: field, Reason: "value is not a date"}
+	}
+	return t, nil
+}
+
+// monthValue reads a month from a JSON number or a query string digit
+func monthValue(value interface{}) (int, error) {
+	var month int
+	switch v := value.(type) {
+	case float64:
+		month = int(v)
+		if float64(month) != v {
+			return 0, fmt.Errorf("month %v is not whole", v)
+		}
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, err
+		}
+		month = n
+	default:
+		return 0, fmt.Errorf("month must be a number")
+	}
+	if month < 1 || month > 12 {
+		return 0, fmt.Errorf("month %d is out of range", month)
+	}
+	return month, nil
+}
+
+// relationExpr builds EXISTS subqueries matching rows of table by their
+// related rows, so a row is returned once however many related rows match.
+func relationExpr(table string, fields entity.QueryFields, rf *model.RelationFilter) (clause.Expression, error) {
+	relation, ok := fields.Relation(rf.Relation)
+	if !ok {
+		return nil, &QueryFieldError{Kind: "relation", Field: rf.Relation}
+	}
+
+	builder := &filterBuilder{table: relation.Table, fields: relation.Fields}
+	conditions := make([]clause.Expression, 0, len(rf.Match))
+	for i := range rf.Match {
+		expr, err := builder.build(&rf.Match[i], 1)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, expr)
+	}
+
+	from := relation.Table
+	link := relation.Table + "." + relation.ForeignKey + " = " + table + ".id"
+	if relation.JoinTable != "" {
+		from = relation.JoinTable + " JOIN " + relation.Table + " ON " + relation.Table + ".id = " +
+			relation.JoinTable + "." + relation.RelatedKey
+		link = relation.JoinTable + "." + relation.ForeignKey + " = " + table + ".id"
+	}
+	exists := func(condition clause.Expression) clause.Expression {
+		sql := "EXISTS (SELECT 1 FROM " + from + " WHERE " + link
+		if condition == nil {
+			return clause.Expr{SQL: sql + ")"}
+		}
+		return clause.Expr{SQL: sql + " AND ?)", Vars: []interface{}{condition}}
+	}
+
+	var anyCondition clause.Expression
+	switch len(conditions) {
+	case 0:
+	case 1:
+		anyCondition = conditions[0]
+	default:
+		anyCondition = clause.Expr{SQL: "(?)", Vars: []interface{}{clause.Or(conditions...)}}
+	}
+
+	switch rf.Mode {
+	case "", model.RelationAny:
+		return exists(anyCondition), nil
+	case model.RelationNone:
+		return clause.Expr{SQL: "NOT ?", Vars: []interface{}{exists(anyCondition)}}, nil
+	case model.RelationAll:
+		if len(conditions) == 0 {
+			return exists(nil), nil
+		}
+		all := make([]clause.Expression, 0, len(conditions))
+		for _, condition := range conditions {
+			all = append(all, exists(condition))
+		}
+		if len(all) == 1 {
+			return all[0], nil
+		}
+		return clause.Expr{SQL: "(?)", Vars: []interface{}{clause.And(all...)}}, nil
+	}
+	return nil, &FilterError{Field: rf.Relation, Reason: fmt.Sprintf("unknown relation mode %q", rf.Mode)}
+}
+
+// likeEscaper escapes the LIKE wildcards so user input matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
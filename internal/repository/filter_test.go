@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dryRunDB builds statements for Postgres without ever connecting
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open dry run db: %v", err)
+	}
+	return db
+}
+
+// renderSQL returns the SQL and bind values expr builds to
+func renderSQL(t *testing.T, expr clause.Expression) (string, []interface{}) {
+	t.Helper()
+	stmt := &gorm.Statement{DB: dryRunDB(t), Clauses: map[string]clause.Clause{}}
+	expr.Build(stmt)
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func personFilterBuilder() *filterBuilder {
+	return &filterBuilder{table: "persons", fields: (&entity.Person{}).QueryFields()}
+}
+
+func TestFilterBuilderConditions(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter model.Filter
+		sql    string
+		vars   []interface{}
+	}{
+		{
+			name:   "eq",
+			filter: model.Filter{Field: "first_name", Op: model.FilterEq, Value: "Ann"},
+			sql:    `"persons"."first_name" = $1`,
+			vars:   []interface{}{"Ann"},
+		},
+		{
+			name:   "ne",
+			filter: model.Filter{Field: "first_name", Op: model.FilterNe, Value: "Ann"},
+			sql:    `"persons"."first_name" <> $1`,
+			vars:   []interface{}{"Ann"},
+		},
+		{
+			name:   "contains escapes wildcards",
+			filter: model.Filter{Field: "first_name", Op: model.FilterContains, Value: `50%_a\`},
+			sql:    `"persons"."first_name" ILIKE $1`,
+			vars:   []interface{}{`%50\%\_a\\%`},
+		},
+		{
+			name:   "starts with escapes wildcards",
+			filter: model.Filter{Field: "last_name", Op: model.FilterStartsWith, Value: "S_"},
+			sql:    `"persons"."last_name" LIKE $1`,
+			vars:   []interface{}{`S\_%`},
+		},
+		{
+			name:   "in",
+			filter: model.Filter{Field: "first_name", Op: model.FilterIn, Value: []interface{}{"a", "b"}},
+			sql:    `"persons"."first_name" IN ($1,$2)`,
+			vars:   []interface{}{"a", "b"},
+		},
+		{
+			name:   "not in",
+			filter: model.Filter{Field: "first_name", Op: model.FilterNotIn, Value: []interface{}{"a", "b"}},
+			sql:    `"persons"."first_name" NOT IN ($1,$2)`,
+			vars:   []interface{}{"a", "b"},
+		},
+		{
+			name:   "is null counts empty text",
+			filter: model.Filter{Field: "nickname", Op: model.FilterIsNull, Value: true},
+			sql:    `NULLIF(CAST("persons"."nickname" AS text), '') IS NULL`,
+		},
+		{
+			name:   "is not null",
+			filter: model.Filter{Field: "nickname", Op: model.FilterIsNull, Value: false},
+			sql:    `NULLIF(CAST("persons"."nickname" AS text), '') IS NOT NULL`,
+		},
+		{
+			name:   "month from a JSON number",
+			filter: model.Filter{Field: "created_at", Op: model.FilterMonth, Value: 2.0},
+			sql:    `EXTRACT(MONTH FROM "persons"."created_at") = $1`,
+			vars:   []interface{}{2},
+		},
+		{
+			name:   "month from a query string",
+			filter: model.Filter{Field: "created_at", Op: model.FilterMonth, Value: "12"},
+			sql:    `EXTRACT(MONTH FROM "persons"."created_at") = $1`,
+			vars:   []interface{}{12},
+		},
+		{
+			name:   "date comparison parses the value",
+			filter: model.Filter{Field: "created_at", Op: model.FilterGte, Value: "2025-11-01"},
+			sql:    `"persons"."created_at" >= $1`,
+			vars:   []interface{}{time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "or group",
+			filter: model.Filter{Or: []model.Filter{
+				{Field: "first_name", Op: model.FilterEq, Value: "Ann"},
+				{Field: "last_name", Op: model.FilterEq, Value: "Lee"},
+			}},
+			sql:  `(("persons"."first_name" = $1 OR "persons"."last_name" = $2))`,
+			vars: []interface{}{"Ann", "Lee"},
+		},
+		{
+			name: "group of one is the member alone",
+			filter: model.Filter{And: []model.Filter{
+				{Field: "first_name", Op: model.FilterEq, Value: "Ann"},
+			}},
+			sql:  `"persons"."first_name" = $1`,
+			vars: []interface{}{"Ann"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := personFilterBuilder().build(&tt.filter, 1)
+			if err != nil {
+				t.Fatalf("build: %v", err)
+			}
+			sql, vars := renderSQL(t, expr)
+			if sql != tt.sql {
+				t.Errorf("sql = %s, want %s", sql, tt.sql)
+			}
+			if len(vars) != len(tt.vars) {
+				t.Fatalf("vars = %#v, want %#v", vars, tt.vars)
+			}
+			for i := range vars {
+				if want, ok := tt.vars[i].(time.Time); ok {
+					if got, ok := vars[i].(time.Time); !ok || !got.Equal(want) {
+						t.Errorf("vars[%d] = %#v, want %v", i, vars[i], want)
+					}
+					continue
+				}
+				if !reflect.DeepEqual(vars[i], tt.vars[i]) {
+					t.Errorf("vars[%d] = %#v, want %#v", i, vars[i], tt.vars[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilterBuilderRejects(t *testing.T) {
+	condition := model.Filter{Field: "first_name", Op: model.FilterEq, Value: "Ann"}
+	tooMany := make([]model.Filter, maxFilterConditions+1)
+	for i := range tooMany {
+		tooMany[i] = condition
+	}
+	deep := condition
+	for i := 0; i < maxFilterDepth; i++ {
+		deep = model.Filter{And: []model.Filter{deep}}
+	}
+
+	tests := []struct {
+		name      string
+		filter    model.Filter
+		fieldErr  bool // a QueryFieldError rather than a FilterError
+		reasonHas string
+	}{
+		{name: "field outside the whitelist", filter: model.Filter{Field: "password", Op: model.FilterEq, Value: "x"}, fieldErr: true},
+		{name: "missing field", filter: model.Filter{Op: model.FilterEq, Value: "x"}, reasonHas: "needs a field"},
+		{name: "number for a text column", filter: model.Filter{Field: "first_name", Op: model.FilterEq, Value: 1.0}, reasonHas: "must be a string"},
+		{name: "boolean for a text column", filter: model.Filter{Field: "first_name", Op: model.FilterEq, Value: true}, reasonHas: "must be a string"},
+		{name: "number in a list", filter: model.Filter{Field: "first_name", Op: model.FilterIn, Value: []interface{}{"a", 2.0}}, reasonHas: "must be a string"},
+		{name: "number for a date column", filter: model.Filter{Field: "created_at", Op: model.FilterGt, Value: 1.0}, reasonHas: "not a date"},
+		{name: "malformed date", filter: model.Filter{Field: "created_at", Op: model.FilterGt, Value: "yesterday-ish"}, reasonHas: "not a date"},
+		{name: "is null without a boolean", filter: model.Filter{Field: "nickname", Op: model.FilterIsNull, Value: "yes"}, reasonHas: "true or false"},
+		{name: "in without a list", filter: model.Filter{Field: "first_name", Op: model.FilterIn, Value: "a"}, reasonHas: "non-empty list"},
+		{name: "in with an empty list", filter: model.Filter{Field: "first_name", Op: model.FilterIn, Value: []interface{}{}}, reasonHas: "non-empty list"},
+		{name: "month of a text column", filter: model.Filter{Field: "first_name", Op: model.FilterMonth, Value: 2.0}, reasonHas: "date field"},
+		{name: "month out of range", filter: model.Filter{Field: "created_at", Op: model.FilterMonth, Value: 13.0}, reasonHas: "1 to 12"},
+		{name: "contains on a date column", filter: model.Filter{Field: "created_at", Op: model.FilterContains, Value: "2025"}, reasonHas: "text field"},
+		{name: "unknown operator", filter: model.Filter{Field: "first_name", Op: "like", Value: "a"}, reasonHas: "unknown operator"},
+		{name: "condition that is also a group", filter: model.Filter{Field: "first_name", And: []model.Filter{condition}}, reasonHas: "cannot also be a group"},
+		{name: "group with and and or", filter: model.Filter{And: []model.Filter{condition}, Or: []model.Filter{condition}}, reasonHas: "either and or or"},
+		{name: "too many conditions", filter: model.Filter{Or: tooMany}, reasonHas: "more than"},
+		{name: "nested too deep", filter: deep, reasonHas: "nest deeper"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := personFilterBuilder().build(&tt.filter, 1)
+			if tt.fieldErr {
+				var fieldErr *QueryFieldError
+				if !errors.As(err, &fieldErr) {
+					t.Fatalf("err = %v, want a QueryFieldError", err)
+				}
+				return
+			}
+			var filterErr *FilterError
+			if !errors.As(err, &filterErr) {
+				t.Fatalf("err = %v, want a FilterError", err)
+			}
+			if !strings.Contains(filterErr.Reason, tt.reasonHas) {
+				t.Errorf("reason = %q, want it to mention %q", filterErr.Reason, tt.reasonHas)
+			}
+		})
+	}
+}
+
+func TestMonthValue(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		month   int
+		invalid bool
+	}{
+		{value: 1.0, month: 1},
+		{value: 12.0, month: 12},
+		{value: "7", month: 7},
+		{value: 0.0, invalid: true},
+		{value: 13.0, invalid: true},
+		{value: 2.5, invalid: true},
+		{value: "", invalid: true},
+		{value: "feb", invalid: true},
+		{value: true, invalid: true},
+		{value: nil, invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.value), func(t *testing.T) {
+			month, err := monthValue(tt.value)
+			if tt.invalid {
+				if err == nil {
+					t.Fatalf("monthValue(%#v) = %d, want an error", tt.value, month)
+				}
+				return
+			}
+			if err != nil || month != tt.month {
+				t.Fatalf("monthValue(%#v) = %d, %v, want %d", tt.value, month, err, tt.month)
+			}
+		})
+	}
+}
+
+func TestRelationExpr(t *testing.T) {
+	fields := (&entity.Person{}).QueryFields()
+	work := model.Filter{Field: "name", Op: model.FilterEq, Value: "Work"}
+	family := model.Filter{Field: "name", Op: model.FilterEq, Value: "Family"}
+	const tagged = `EXISTS (SELECT 1 FROM persons_tags JOIN tags ON tags.id = persons_tags.tag_id WHERE persons_tags.person_id = persons.id`
+
+	tests := []struct {
+		name   string
+		filter model.RelationFilter
+		sql    string
+		vars   []interface{}
+	}{
+		{
+			name:   "any without conditions",
+			filter: model.RelationFilter{Relation: "tags"},
+			sql:    tagged + `)`,
+		},
+		{
+			name:   "none without conditions",
+			filter: model.RelationFilter{Relation: "phones", Mode: model.RelationNone},
+			sql:    `NOT EXISTS (SELECT 1 FROM phones WHERE phones.person_id = persons.id)`,
+		},
+		{
+			name:   "any of two conditions",
+			filter: model.RelationFilter{Relation: "tags", Match: []model.Filter{work, family}},
+			sql:    tagged + ` AND (("tags"."name" = $1 OR "tags"."name" = $2)))`,
+			vars:   []interface{}{"Work", "Family"},
+		},
+		{
+			name:   "all asks for a related row per condition",
+			filter: model.RelationFilter{Relation: "tags", Mode: model.RelationAll, Match: []model.Filter{work, family}},
+			sql:    `(((` + tagged + ` AND "tags"."name" = $1)) AND (` + tagged + ` AND "tags"."name" = $2))))`,
+			vars:   []interface{}{"Work", "Family"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := relationExpr("persons", fields, &tt.filter)
+			if err != nil {
+				t.Fatalf("relationExpr: %v", err)
+			}
+			sql, vars := renderSQL(t, expr)
+			if sql != tt.sql {
+				t.Errorf("sql = %s\nwant  %s", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(vars, tt.vars) && (len(vars) > 0 || len(tt.vars) > 0) {
+				t.Errorf("vars = %#v, want %#v", vars, tt.vars)
+			}
+		})
+	}
+
+	t.Run("unknown relation", func(t *testing.T) {
+		_, err := relationExpr("persons", fields, &model.RelationFilter{Relation: "users"})
+		var fieldErr *QueryFieldError
+		if !errors.As(err, &fieldErr) {
+			t.Fatalf("err = %v, want a QueryFieldError", err)
+		}
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := relationExpr("persons", fields, &model.RelationFilter{Relation: "tags", Mode: "some"})
+		var filterErr *FilterError
+		if !errors.As(err, &filterErr) {
+			t.Fatalf("err = %v, want a FilterError", err)
+		}
+	})
+
+	t.Run("condition on a field the relation does not allow", func(t *testing.T) {
+		filter := model.RelationFilter{Relation: "phones", Match: []model.Filter{{Field: "person", Op: model.FilterEq, Value: "x"}}}
+		_, err := relationExpr("persons", fields, &filter)
+		var fieldErr *QueryFieldError
+		if !errors.As(err, &fieldErr) {
+			t.Fatalf("err = %v, want a QueryFieldError", err)
+		}
+	})
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"plain":    "plain",
+		"100%":     `100\%`,
+		"a_b":      `a\_b`,
+		`back\`:    `back\\`,
+		`\%_`:      `\\\%\_`,
+		"":         "",
+		"naïve %s": `naïve \%s`,
+	}
+	for in, want := range tests {
+		if got := escapeLike(in); got != want {
+			t.Errorf("escapeLike(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
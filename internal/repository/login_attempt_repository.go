@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepository struct {
+	Repository[entity.LoginAttempt]
+	Log *logrus.Logger
+}
+
+func NewLoginAttemptRepository(log *logrus.Logger) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		Log: log,
+	}
+}
+
+func (r *LoginAttemptRepository) FindByIDs(ctx context.Context, db *gorm.DB, attempts *[]entity.LoginAttempt, ids []string) error {
+	return db.WithContext(ctx).Where("id IN ?", ids).Find(attempts).Error
+}
+
+// RecordFailure atomically counts a failure for id. Failures older than
+// resetBefore (unix seconds) are forgotten and the count restarts at one.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, db *gorm.DB, attempt *entity.LoginAttempt, now int64, resetBefore int64) error {
+	attempt.Failures = 1
+	attempt.LastFailureAt = now
+
+	return db.WithContext(ctx).
+		Clauses(
+			clause.OnConflict{
+				Columns: []clause.Column{{Name: "id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"failures":        gorm.Expr("CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END", resetBefore),
+					"last_failure_at": now,
+				}),
+			},
+			clause.Returning{},
+		).
+		Create(attempt).Error
+}
+
+func (r *LoginAttemptRepository) LockUntil(ctx context.Context, db *gorm.DB, id string, until int64) error {
+	return db.WithContext(ctx).
+		Model(&entity.LoginAttempt{}).
+		Where("id = ?", id).
+		Update("locked_until", until).
+		Error
+}
+
+func (r *LoginAttemptRepository) DeleteByID(ctx context.Context, db *gorm.DB, id string) error {
+	return db.WithContext(ctx).Where("id = ?", id).Delete(&entity.LoginAttempt{}).Error
+}
@@ -1,9 +1,226 @@
 package repository
 
 import (
+	"codename-rl/internal/entity"
+	"errors"
+
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 func AutoMigrate(db *gorm.DB, entities ...interface{}) error {
 	return db.AutoMigrate(entities...)
 }
+
+// scopedNameTables had a globally unique name column before names became
+// unique per owner (per person for important dates).
+var scopedNameTables = []string{"tags", "relationships", "important_dates"}
+
+// DropGlobalNameUniques removes the old global unique constraint on name so
+// AutoMigrate can replace it with the scoped composite index. Names that were
+// unique system wide are unique per owner too, so existing rows need no change.
+// The constraint went by different names across GORM versions, all are dropped.
+func DropGlobalNameUniques(db *gorm.DB) error {
+	for _, table := range scopedNameTables {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+
+		for _, constraint := range []string{"uni_" + table + "_name", table + "_name_key"} {
+			if err := db.Exec("ALTER TABLE " + table + " DROP CONSTRAINT IF EXISTS " + constraint).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := db.Exec("DROP INDEX IF EXISTS idx_" + table + "_name").Error; err != nil {
+			return err
+		}
+	}
+
+	// Tag and relationship names moved on from per user to per address book
+	for _, index := range []string{"idx_tags_user_id_name", "idx_relationships_user_id_name"} {
+		if err := db.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropGlobalPhoneNumberUnique removes the old global unique constraint on
+// phones.number so AutoMigrate can replace it with the per person index.
+// Numbers that were unique system wide are unique per person too.
+func DropGlobalPhoneNumberUnique(db *gorm.DB) error {
+	if !db.Migrator().HasTable("phones") {
+		return nil
+	}
+
+	for _, constraint := range []string{"uni_phones_number", "phones_number_key"} {
+		if err := db.Exec("ALTER TABLE phones DROP CONSTRAINT IF EXISTS " + constraint).Error; err != nil {
+			return err
+		}
+	}
+	return db.Exec("DROP INDEX IF EXISTS idx_phones_number").Error
+}
+
+// MergeRelationshipJoinTables moves the links Relationship.Persons kept in
+// person_relationships into persons_relationships, the join table Person
+// already used, so both sides see the same links. The old table goes once
+// every link is copied.
+func MergeRelationshipJoinTables(db *gorm.DB) error {
+	if !db.Migrator().HasTable("person_relationships") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO persons_relationships (person_id, relationship_id) " +
+			"SELECT person_id, relationship_id FROM person_relationships ON CONFLICT DO NOTHING").Error; err != nil {
+			return err
+		}
+		return tx.Exec("DROP TABLE person_relationships").Error
+	})
+}
+
+// personChildForeignKeys were created for the Person of a phone or important
+// date. Once Person has many of them, GORM names the same key after that side.
+var personChildForeignKeys = map[string]string{
+	"phones":          "fk_phones_person",
+	"important_dates": "fk_important_dates_person",
+}
+
+// DropPersonChildForeignKeys removes the foreign keys AutoMigrate recreates
+// under their has-many name, so the tables do not end up with two.
+func DropPersonChildForeignKeys(db *gorm.DB) error {
+	for table, constraint := range personChildForeignKeys {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+		if err := db.Exec("ALTER TABLE " + table + " DROP CONSTRAINT IF EXISTS " + constraint).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessionExpiryTables stored expires_at in Unix seconds next to times in
+// milliseconds
+var sessionExpiryTables = []string{"sessions", "refresh_tokens"}
+
+// MigrateSessionExpiryToMillis converts the expires_at values still in
+// seconds. Any second count below 10^11 lies before the year 5138, while
+// millisecond values passed it in 1973, so rows are only ever converted once.
+func MigrateSessionExpiryToMillis(db *gorm.DB) error {
+	for _, table := range sessionExpiryTables {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+		if err := db.Exec("UPDATE " + table + " SET expires_at = expires_at * 1000 WHERE expires_at > 0 AND expires_at < 100000000000").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateVerifiedAtToMillis converts the verified_at values that OIDC sign-in
+// stored in seconds, by the same bound as MigrateSessionExpiryToMillis.
+func MigrateVerifiedAtToMillis(db *gorm.DB) error {
+	if !db.Migrator().HasTable("users") {
+		return nil
+	}
+	return db.Exec("UPDATE users SET verified_at = verified_at * 1000 WHERE verified_at > 0 AND verified_at < 100000000000").Error
+}
+
+// addressBookTables hold rows that belong to an address book
+var addressBookTables = []string{"persons", "tags", "relationships"}
+
+// AssignPersonalAddressBooks moves rows created before address books existed
+// into the personal book of the user who created them, creating the book on
+// the way. Every user's rows land in their own book, so names that were unique
+// per user stay unique per book.
+func AssignPersonalAddressBooks(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range addressBookTables {
+			var userIDs []string
+			if err := tx.Table(table).
+				Where("address_book_id IS NULL OR address_book_id = ''").
+				Distinct().
+				Pluck("user_id", &userIDs).Error; err != nil {
+				return err
+			}
+
+			for _, userID := range userIDs {
+				member := new(entity.AddressBookMember)
+				err := tx.Where("user_id = ? AND role = ?", userID, entity.AddressBookRoleOwner).
+					Order("created_at ASC").
+					Take(member).Error
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					member, err = new(AddressBookRepository).CreateWithOwner(tx, &entity.AddressBook{
+						ID:   uuid.New().String(),
+						Name: "Personal",
+					}, userID)
+				}
+				if err != nil {
+					return err
+				}
+
+				if err := tx.Table(table).
+					Where("user_id = ? AND (address_book_id IS NULL OR address_book_id = '')", userID).
+					Update("address_book_id", member.AddressBookID).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// searchVectors are the generated tsvector columns full-text search runs on.
+// Postgres keeps them current on every write, and each gets a GIN index. The
+// simple configuration is used because names and numbers should not be
+// stemmed as English words.
+var searchVectors = map[string]string{
+	"persons": "setweight(to_tsvector('simple', coalesce(first_name, '') || ' ' || coalesce(last_name, '') || ' ' || coalesce(nickname, '')), 'A') || " +
+		"setweight(to_tsvector('simple', coalesce(description, '')), 'C')",
+	"phones": "setweight(to_tsvector('simple', coalesce(number, '') || ' ' || regexp_replace(coalesce(number, ''), '[^0-9]', '', 'g')), 'A') || " +
+		"setweight(to_tsvector('simple', coalesce(name, '')), 'B')",
+	"tags":            "setweight(to_tsvector('simple', coalesce(name, '')), 'A')",
+	"important_dates": "setweight(to_tsvector('simple', coalesce(name, '')), 'A')",
+}
+
+// CreateSearchVectors adds the search_vector columns and their indexes. The
+// columns are left out of the entities so GORM never writes to them.
+func CreateSearchVectors(db *gorm.DB) error {
+	for table, expression := range searchVectors {
+		if err := db.Exec("ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS search_vector tsvector " +
+			"GENERATED ALWAYS AS (" + expression + ") STORED").Error; err != nil {
+			return err
+		}
+		if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_" + table + "_search_vector ON " + table +
+			" USING GIN (search_vector)").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trigramColumns are matched by similarity for fuzzy name search
+var trigramColumns = map[string][]string{
+	"persons": {"first_name", "last_name", "nickname"},
+}
+
+// CreateTrigramIndexes enables pg_trgm and indexes the fuzzy searchable
+// columns so the % operator does not scan every row.
+func CreateTrigramIndexes(db *gorm.DB) error {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		return err
+	}
+	for table, columns := range trigramColumns {
+		for _, column := range columns {
+			if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_" + table + "_" + column + "_trgm ON " + table +
+				" USING GIN (" + column + " gin_trgm_ops)").Error; err != nil {
+				return err
+			}
+		}
+	}
+	// The full name FindSimilar matches, in the form of personFullName
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_persons_full_name_trgm ON persons " +
+		"USING GIN ((coalesce(first_name, '') || ' ' || coalesce(last_name, '')) gin_trgm_ops)").Error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type OidcIdentityRepository struct {
+	Repository[entity.OidcIdentity]
+	Log *logrus.Logger
+}
+
+func NewOidcIdentityRepository(log *logrus.Logger) *OidcIdentityRepository {
+	return &OidcIdentityRepository{
+		Log: log,
+	}
+}
+
+func (r *OidcIdentityRepository) FindByProviderAndSubject(ctx context.Context, db *gorm.DB, identity *entity.OidcIdentity, provider string, subject string) error {
+	return db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).Take(identity).Error
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type OidcStateRepository struct {
+	Repository[entity.OidcState]
+	Log *logrus.Logger
+}
+
+func NewOidcStateRepository(log *logrus.Logger) *OidcStateRepository {
+	return &OidcStateRepository{
+		Log: log,
+	}
+}
+
+func (r *OidcStateRepository) FindByHash(ctx context.Context, db *gorm.DB, state *entity.OidcState, hash string) error {
+	return db.WithContext(ctx).Where("state_hash = ?", hash).Take(state).Error
+}
+
+func (r *OidcStateRepository) DeleteExpired(ctx context.Context, db *gorm.DB, now int64) error {
+	return db.WithContext(ctx).Where("expires_at < ?", now).Delete(&entity.OidcState{}).Error
+}
@@ -19,12 +19,12 @@ func NewOtpRepository(log *logrus.Logger) *OtpRepository {
 	}
 }
 
-func (r *OtpRepository) FindByToken(ctx context.Context, db *gorm.DB, otp *entity.Otp, token string) error {
-	return db.WithContext(ctx).Where("token = ?", token).First(otp).Error
+func (r *OtpRepository) FindByToken(ctx context.Context, db *gorm.DB, otp *entity.Otp, token string, purpose entity.OtpPurpose) error {
+	return db.WithContext(ctx).Where("token = ? AND purpose = ?", token, purpose).First(otp).Error
 }
 
-func (r *OtpRepository) FindByUserID(ctx context.Context, db *gorm.DB, otp *entity.Otp, userID string) error {
-	if err := db.WithContext(ctx).Where("user_id = ?", userID).First(otp).Error; err != nil {
+func (r *OtpRepository) FindByUserID(ctx context.Context, db *gorm.DB, otp *entity.Otp, userID string, purpose entity.OtpPurpose) error {
+	if err := db.WithContext(ctx).Where("user_id = ? AND purpose = ?", userID, purpose).Order("created_at DESC").First(otp).Error; err != nil {
 		return err
 	}
 	return nil
@@ -37,10 +37,24 @@ func (r *OtpRepository) FindWithUser(ctx context.Context, db *gorm.DB, otp *enti
 	return nil
 }
 
+// ConsumeAttempt counts one verification attempt against the OTP and reports
+// false once maxAttempts have been used. The row stays locked until commit.
+func (r *OtpRepository) ConsumeAttempt(ctx context.Context, db *gorm.DB, id string, maxAttempts int) (bool, error) {
+	result := db.WithContext(ctx).
+		Model(&entity.Otp{}).
+		Where("id = ? AND attempts < ?", id, maxAttempts).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
 func (r *OtpRepository) DeleteByUserID(ctx context.Context, db *gorm.DB, userID string) error {
 	return db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.Otp{}).Error
 }
 
 func (r *OtpRepository) DeleteByToken(ctx context.Context, db *gorm.DB, Token string) error {
-	return db.WithContext(ctx).Where("user_id = ?", Token).Delete(&entity.Otp{}).Error
+	return db.WithContext(ctx).Where("token = ?", Token).Delete(&entity.Otp{}).Error
+}
+
+func (r *OtpRepository) DeleteByUserIDAndPurpose(ctx context.Context, db *gorm.DB, userID string, purpose entity.OtpPurpose) error {
+	return db.WithContext(ctx).Where("user_id = ? AND purpose = ?", userID, purpose).Delete(&entity.Otp{}).Error
 }
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"codename-rl/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OwnerScope limits a query to the rows that userID can see
+type OwnerScope func(userID string) func(db *gorm.DB) *gorm.DB
+
+// inMemberBook scopes tables that carry an address_book_id column to the
+// books userID is a member of
+func inMemberBook(table string) OwnerScope {
+	return func(userID string) func(db *gorm.DB) *gorm.DB {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where(table+".address_book_id IN (SELECT address_book_id FROM address_book_members WHERE user_id = ?)", userID)
+		}
+	}
+}
+
+// throughMemberPerson scopes tables whose rows belong to a person kept in one
+// of the books userID is a member of
+func throughMemberPerson(table string) OwnerScope {
+	return func(userID string) func(db *gorm.DB) *gorm.DB {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where(table+".person_id IN (SELECT persons.id FROM persons"+
+				" JOIN address_book_members ON address_book_members.address_book_id = persons.address_book_id"+
+				" WHERE address_book_members.user_id = ?)", userID)
+		}
+	}
+}
+
+// OwnedRepository is the Repository for entities kept in an address book.
+// Reads and writes only ever see rows of the caller's books, so IDs from other
+// books behave exactly like IDs that do not exist. Roles are checked by the
+// usecases.
+type OwnedRepository[T any] struct {
+	Owner OwnerScope
+}
+
+func (r *OwnedRepository[T]) Create(db *gorm.DB, entity *T) error {
+	return db.Create(entity).Error
+}
+
+func (r *OwnedRepository[T]) FindById(db *gorm.DB, entity *T, id any, userID string) error {
+	return db.Scopes(r.Owner(userID)).Where("id = ?", id).Take(entity).Error
+}
+
+func (r *OwnedRepository[T]) ExistsById(db *gorm.DB, id any, userID string) (bool, error) {
+	var exists bool
+	err := db.Model(new(T)).
+		Scopes(r.Owner(userID)).
+		Select("count(*) > 0").
+		Where("id = ?", id).
+		Find(&exists).Error
+	return exists, err
+}
+
+func (r *OwnedRepository[T]) FindAll(db *gorm.DB, result *[]T, q *model.Query, userID string) (*model.PageInfo, error) {
+	return findAll(db.Model(new(T)).Scopes(r.Owner(userID)), result, q)
+}
+
+// Matching narrows db to the rows of T that userID can see and q matches, as
+// FindAll does before it sorts and pages them.
+func (r *OwnedRepository[T]) Matching(db *gorm.DB, q *model.Query, userID string) (*gorm.DB, error) {
+	return matching[T](db.Model(new(T)).Scopes(r.Owner(userID)), q)
+}
+
+// Update writes every column of entity. Unlike Save it never falls back to an
+// insert, which could otherwise overwrite a row owned by someone else.
+func (r *OwnedRepository[T]) Update(db *gorm.DB, entity *T, userID string) error {
+	result := db.Model(entity).
+		Scopes(r.Owner(userID)).
+		Select("*").
+		Omit(clause.Associations).
+		Updates(entity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *OwnedRepository[T]) Delete(db *gorm.DB, entity *T, userID string) error {
+	result := db.Scopes(r.Owner(userID)).Delete(entity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -2,20 +2,26 @@ package repository
 
 import (
 	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"database/sql"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PersonRepository struct {
-	Repository[entity.Person]
+	OwnedRepository[entity.Person]
 	Log *logrus.Logger
 }
 
 func NewPersonRepository(log *logrus.Logger) *PersonRepository {
 	return &PersonRepository{
-		Log: log,
+		OwnedRepository: OwnedRepository[entity.Person]{Owner: inMemberBook("persons")},
+		Log:             log,
 	}
 }
 
@@ -28,22 +34,11 @@ func (r *PersonRepository) Create(tx *gorm.DB, person *entity.Person, tagIDs []s
 		return nil
 	}
 
-	var count int64
-	if err := tx.Model(&entity.Tag{}).
-		Where("id IN ?", tagIDs).
-		Count(&count).Error; err != nil {
+	tags, err := tagsInBook(tx, tagIDs, person.AddressBookID)
+	if err != nil {
 		return err
 	}
 
-	if count != int64(len(tagIDs)) {
-		return fmt.Errorf("one or more tag IDs do not exist")
-	}
-
-	tags := make([]entity.Tag, 0, len(tagIDs))
-	for _, id := range tagIDs {
-		tags = append(tags, entity.Tag{ID: id})
-	}
-
 	if err := tx.Model(person).Association("Tags").Append(tags); err != nil {
 		return err
 	}
@@ -51,32 +46,239 @@ func (r *PersonRepository) Create(tx *gorm.DB, person *entity.Person, tagIDs []s
 	return nil
 }
 
-func (r *PersonRepository) Update(tx *gorm.DB, person *entity.Person, tagIDs []string) error {
-	if err := tx.Save(person).Error; err != nil {
+func (r *PersonRepository) Update(tx *gorm.DB, person *entity.Person, tagIDs []string, userID string) error {
+	if err := r.OwnedRepository.Update(tx, person, userID); err != nil {
 		return err
 	}
 
 	if len(tagIDs) > 0 {
-		var count int64
-		if err := tx.Model(&entity.Tag{}).
-			Where("id IN ?", tagIDs).
-			Count(&count).Error; err != nil {
+		tags, err := tagsInBook(tx, tagIDs, person.AddressBookID)
+		if err != nil {
 			return err
 		}
 
-		if count != int64(len(tagIDs)) {
-			return fmt.Errorf("one or more tag IDs do not exist")
+		if err := tx.Model(person).Association("Tags").Replace(tags); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// FindDetail loads the person with id together with its tags, its
+// relationships and the persons they link, its phones and its important
+// dates, leaving out the relations named in omit. Each relation takes one
+// query however many rows it has.
+func (r *PersonRepository) FindDetail(db *gorm.DB, person *entity.Person, id string, userID string, omit []string) error {
+	db = db.Scopes(r.Owner(userID)).
+		Preload("Tags", func(db *gorm.DB) *gorm.DB {
+			return db.Order("tags.name ASC")
+		}).
+		Preload("Relationships", func(db *gorm.DB) *gorm.DB {
+			return db.Order("relationships.name ASC")
+		}).
+		Preload("Relationships.Persons")
+	if !slices.Contains(omit, "phones") {
+		db = db.Preload("Phones", func(db *gorm.DB) *gorm.DB {
+			return db.Order("phones.name ASC")
+		})
+	}
+	if !slices.Contains(omit, "important_dates") {
+		db = db.Preload("ImportantDates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("important_dates.date ASC")
+		})
+	}
+	return db.Where("persons.id = ?", id).Take(person).Error
+}
+
+// personFullName joins first and last name as idx_persons_full_name_trgm
+// indexes them. concat_ws is not immutable, so it could not be indexed, and
+// trigrams ignore the spare space a missing name leaves.
+const personFullName = "(coalesce(persons.first_name, '') || ' ' || coalesce(persons.last_name, ''))"
 
-		tags := make([]entity.Tag, 0, len(tagIDs))
-		for _, id := range tagIDs {
-			tags = append(tags, entity.Tag{ID: id})
+// personSimilarity scores a person against a name by its closest field
+const personSimilarity = "GREATEST(similarity(persons.first_name, @name), similarity(persons.last_name, @name), " +
+	"similarity(persons.nickname, @name), similarity(" + personFullName + ", @name))"
+
+// FindSimilar finds the persons whose names are trigram similar to name,
+// best match first, and returns the similarity of each.
+func (r *PersonRepository) FindSimilar(db *gorm.DB, persons *[]entity.Person, name string, userID string, limit int) ([]float64, error) {
+	var matches []struct {
+		ID         string
+		Similarity float64
+	}
+	if err := db.Model(&entity.Person{}).
+		Scopes(r.Owner(userID)).
+		Select("persons.id, "+personSimilarity+" AS similarity", sql.Named("name", name)).
+		Where("(persons.first_name % @name OR persons.last_name % @name OR persons.nickname % @name "+
+			"OR "+personFullName+" % @name)", sql.Named("name", name)).
+		Order("similarity DESC").
+		Order("persons.id ASC").
+		Limit(limit).
+		Scan(&matches).Error; err != nil {
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.ID)
+	}
+
+	var found []entity.Person
+	if err := db.Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]entity.Person, len(found))
+	for _, person := range found {
+		byID[person.ID] = person
+	}
+
+	similarities := make([]float64, 0, len(matches))
+	for _, match := range matches {
+		if person, ok := byID[match.ID]; ok {
+			*persons = append(*persons, person)
+			similarities = append(similarities, match.Similarity)
 		}
+	}
+	return similarities, nil
+}
 
-		if err := tx.Model(person).Association("Tags").Replace(tags); err != nil {
-			return err
+// Facets counts the persons q matches: in total, per tag, per relationship,
+// per month they were created, with a phone, and with an important date whose
+// anniversary falls within upcomingDays of from. The relations named in omit
+// are not counted.
+func (r *PersonRepository) Facets(db *gorm.DB, facets *entity.PersonFacets, q *model.Query, userID string, from time.Time, upcomingDays int, omit []string) error {
+	persons, err := r.Matching(db, q, userID)
+	if err != nil {
+		return err
+	}
+	// The counts of omitted relations are left out, they would tell which
+	// persons have such rows
+	columns := "count(*) AS total"
+	var vars []interface{}
+	if !slices.Contains(omit, "phones") {
+		columns += ", count(*) FILTER (WHERE EXISTS (SELECT 1 FROM phones WHERE phones.person_id = persons.id)) AS with_phone"
+	}
+	if !slices.Contains(omit, "important_dates") {
+		columns += ", count(*) FILTER (WHERE EXISTS (SELECT 1 FROM important_dates WHERE important_dates.person_id = persons.id AND ?)) AS with_upcoming_date"
+		vars = append(vars, upcomingDate(from, upcomingDays))
+	}
+	if err := persons.Select(columns, vars...).Scan(facets).Error; err != nil {
+		return err
+	}
+
+	ids, err := r.Matching(db, q, userID)
+	if err != nil {
+		return err
+	}
+	ids = ids.Select("persons.id")
+
+	if err := db.Table("persons_tags").
+		Select("tags.id, tags.name, count(*) AS count").
+		Joins("JOIN tags ON tags.id = persons_tags.tag_id").
+		Where("persons_tags.person_id IN (?)", ids).
+		Group("tags.id, tags.name").
+		Order("count DESC, tags.name ASC").
+		Scan(&facets.Tags).Error; err != nil {
+		return err
+	}
+
+	if err := db.Table("persons_relationships").
+		Select("relationships.id, relationships.name, count(*) AS count").
+		Joins("JOIN relationships ON relationships.id = persons_relationships.relationship_id").
+		Where("persons_relationships.person_id IN (?)", ids).
+		Group("relationships.id, relationships.name").
+		Order("count DESC, relationships.name ASC").
+		Scan(&facets.Relationships).Error; err != nil {
+		return err
+	}
+
+	months, err := r.Matching(db, q, userID)
+	if err != nil {
+		return err
+	}
+	return months.
+		Select("to_char(persons.created_at, 'YYYY-MM') AS id, count(*) AS count").
+		Group("to_char(persons.created_at, 'YYYY-MM')").
+		Order("id ASC").
+		Scan(&facets.CreatedMonths).Error
+}
+
+// upcomingDate matches the important dates whose anniversary, in any year,
+// falls between from and days later.
+func upcomingDate(from time.Time, days int) clause.Expr {
+	if days >= 365 {
+		return clause.Expr{SQL: "TRUE"}
+	}
+	const monthDay = "to_char(important_dates.date, 'MMDD')"
+	start, end := from.Format("0102"), from.AddDate(0, 0, days).Format("0102")
+	if start <= end {
+		return clause.Expr{SQL: monthDay + " BETWEEN ? AND ?", Vars: []interface{}{start, end}}
+	}
+	// The window runs over the new year
+	return clause.Expr{SQL: "(" + monthDay + " >= ? OR " + monthDay + " <= ?)", Vars: []interface{}{start, end}}
+}
+
+// uniqueIDs drops repeated IDs, keeping the first of each in order
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
 		}
 	}
+	return unique
+}
 
-	return nil
+// tagsInBook checks that every tag ID is kept in bookID and returns ID-only
+// tags, once per ID however often it was given
+func tagsInBook(tx *gorm.DB, tagIDs []string, bookID string) ([]entity.Tag, error) {
+	tagIDs = uniqueIDs(tagIDs)
+
+	var count int64
+	if err := tx.Model(&entity.Tag{}).
+		Where("id IN ? AND address_book_id = ?", tagIDs, bookID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+
+	if count != int64(len(tagIDs)) {
+		return nil, fmt.Errorf("one or more tag IDs do not exist: %w", gorm.ErrRecordNotFound)
+	}
+
+	tags := make([]entity.Tag, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		tags = append(tags, entity.Tag{ID: id})
+	}
+	return tags, nil
+}
+
+// personsInBook checks that every person ID is kept in bookID and returns
+// ID-only persons, once per ID however often it was given
+func personsInBook(tx *gorm.DB, personIDs []string, bookID string) ([]entity.Person, error) {
+	personIDs = uniqueIDs(personIDs)
+
+	var count int64
+	if err := tx.Model(&entity.Person{}).
+		Where("id IN ? AND address_book_id = ?", personIDs, bookID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+
+	if count != int64(len(personIDs)) {
+		return nil, fmt.Errorf("one or more person IDs do not exist: %w", gorm.ErrRecordNotFound)
+	}
+
+	persons := make([]entity.Person, 0, len(personIDs))
+	for _, id := range personIDs {
+		persons = append(persons, entity.Person{ID: id})
+	}
+	return persons, nil
 }
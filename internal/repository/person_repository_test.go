@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUpcomingDate(t *testing.T) {
+	const monthDay = "to_char(important_dates.date, 'MMDD')"
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		from time.Time
+		days int
+		sql  string
+		vars []interface{}
+	}{
+		{
+			name: "window inside the year",
+			from: day(time.March, 10),
+			days: 30,
+			sql:  monthDay + " BETWEEN $1 AND $2",
+			vars: []interface{}{"0310", "0409"},
+		},
+		{
+			name: "today only",
+			from: day(time.June, 1),
+			days: 0,
+			sql:  monthDay + " BETWEEN $1 AND $2",
+			vars: []interface{}{"0601", "0601"},
+		},
+		{
+			name: "window ending on the last day of the year",
+			from: day(time.December, 1),
+			days: 30,
+			sql:  monthDay + " BETWEEN $1 AND $2",
+			vars: []interface{}{"1201", "1231"},
+		},
+		{
+			name: "window over the new year",
+			from: day(time.December, 20),
+			days: 30,
+			sql:  "(" + monthDay + " >= $1 OR " + monthDay + " <= $2)",
+			vars: []interface{}{"1220", "0119"},
+		},
+		{
+			name: "window from new year's eve",
+			from: day(time.December, 31),
+			days: 1,
+			sql:  "(" + monthDay + " >= $1 OR " + monthDay + " <= $2)",
+			vars: []interface{}{"1231", "0101"},
+		},
+		{
+			name: "a whole year",
+			from: day(time.July, 4),
+			days: 365,
+			sql:  "TRUE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, vars := renderSQL(t, upcomingDate(tt.from, tt.days))
+			if sql != tt.sql {
+				t.Errorf("sql = %s\nwant  %s", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(vars, tt.vars) {
+				t.Errorf("vars = %#v, want %#v", vars, tt.vars)
+			}
+		})
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []string
+		want []string
+	}{
+		{name: "none", ids: nil, want: []string{}},
+		{name: "no repeats", ids: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "repeats keep the first in order", ids: []string{"b", "a", "b", "a", "c"}, want: []string{"b", "a", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniqueIDs(tt.ids); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("uniqueIDs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	Repository[entity.RecoveryCode]
+	Log *logrus.Logger
+}
+
+func NewRecoveryCodeRepository(log *logrus.Logger) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		Log: log,
+	}
+}
+
+func (r *RecoveryCodeRepository) FindUnusedByUserID(ctx context.Context, db *gorm.DB, codes *[]entity.RecoveryCode, userID string) error {
+	return db.WithContext(ctx).Where("user_id = ? AND used_at = 0", userID).Find(codes).Error
+}
+
+func (r *RecoveryCodeRepository) MarkUsed(ctx context.Context, db *gorm.DB, id string) error {
+	return db.WithContext(ctx).
+		Model(&entity.RecoveryCode{}).
+		Where("id = ?", id).
+		Update("used_at", time.Now().UnixMilli()).
+		Error
+}
+
+func (r *RecoveryCodeRepository) DeleteByUserID(ctx context.Context, db *gorm.DB, userID string) error {
+	return db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error
+}
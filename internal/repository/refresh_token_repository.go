@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository struct {
+	Repository[entity.RefreshToken]
+	Log *logrus.Logger
+}
+
+func NewRefreshTokenRepository(log *logrus.Logger) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		Log: log,
+	}
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, db *gorm.DB, token *entity.RefreshToken, hash string) error {
+	return db.WithContext(ctx).Where("token_hash = ?", hash).Take(token).Error
+}
+
+// MarkRotated flags the token as used. It reports false when the token was
+// already rotated, which lets callers detect concurrent reuse.
+func (r *RefreshTokenRepository) MarkRotated(ctx context.Context, db *gorm.DB, id string) (bool, error) {
+	result := db.WithContext(ctx).
+		Model(&entity.RefreshToken{}).
+		Where("id = ? AND rotated_at = 0", id).
+		Update("rotated_at", time.Now().UnixMilli())
+	return result.RowsAffected == 1, result.Error
+}
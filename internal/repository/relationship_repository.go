@@ -2,28 +2,30 @@ package repository
 
 import (
 	"codename-rl/internal/entity"
-	"fmt"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type RelationshipRepository struct {
-	Repository[entity.Relationship]
+	OwnedRepository[entity.Relationship]
 	Log *logrus.Logger
 }
 
 func NewRelationshipRepository(log *logrus.Logger) *RelationshipRepository {
 	return &RelationshipRepository{
-		Log: log,
+		OwnedRepository: OwnedRepository[entity.Relationship]{Owner: inMemberBook("relationships")},
+		Log:             log,
 	}
 }
 
-func (r *RelationshipRepository) ExistsByName(tx *gorm.DB, relationship *entity.Relationship, name string) (bool, error) {
+// ExistsByName reports whether the address book has another relationship called name;
+// excludeID skips the relationship being renamed.
+func (r *RelationshipRepository) ExistsByName(tx *gorm.DB, name string, bookID string, excludeID string) (bool, error) {
 	var exists bool
-	err := tx.Model(relationship).
+	err := tx.Model(&entity.Relationship{}).
 		Select("count(*) > 0").
-		Where("name = ?", name).
+		Where("address_book_id = ? AND name = ? AND id <> ?", bookID, name, excludeID).
 		Find(&exists).Error
 	return exists, err
 }
@@ -37,22 +39,11 @@ func (r *RelationshipRepository) Create(tx *gorm.DB, relationship *entity.Relati
 		return nil
 	}
 
-	var count int64
-	if err := tx.Model(&entity.Person{}).
-		Where("id IN ?", personIDs).
-		Count(&count).Error; err != nil {
+	persons, err := personsInBook(tx, personIDs, relationship.AddressBookID)
+	if err != nil {
 		return err
 	}
 
-	if count != int64(len(personIDs)) {
-		return fmt.Errorf("one or more person IDs do not exist")
-	}
-
-	persons := make([]entity.Person, 0, len(personIDs))
-	for _, id := range personIDs {
-		persons = append(persons, entity.Person{ID: id})
-	}
-
 	if err := tx.Model(relationship).Association("Persons").Append(persons); err != nil {
 		return err
 	}
@@ -60,28 +51,17 @@ func (r *RelationshipRepository) Create(tx *gorm.DB, relationship *entity.Relati
 	return nil
 }
 
-func (r *RelationshipRepository) Update(tx *gorm.DB, relationship *entity.Relationship, personIDs []string) error {
-	if err := tx.Save(relationship).Error; err != nil {
+func (r *RelationshipRepository) Update(tx *gorm.DB, relationship *entity.Relationship, personIDs []string, userID string) error {
+	if err := r.OwnedRepository.Update(tx, relationship, userID); err != nil {
 		return err
 	}
 
 	if len(personIDs) > 0 {
-		var count int64
-		if err := tx.Model(&entity.Person{}).
-			Where("id IN ?", personIDs).
-			Count(&count).Error; err != nil {
+		persons, err := personsInBook(tx, personIDs, relationship.AddressBookID)
+		if err != nil {
 			return err
 		}
 
-		if count != int64(len(personIDs)) {
-			return fmt.Errorf("one or more person IDs do not exist")
-		}
-
-		persons := make([]entity.Person, 0, len(personIDs))
-		for _, id := range personIDs {
-			persons = append(persons, entity.Person{ID: id})
-		}
-
 		if err := tx.Model(relationship).Association("Persons").Replace(persons); err != nil {
 			return err
 		}
@@ -4,9 +4,13 @@ import (
 	"codename-rl/internal/entity"
 	"codename-rl/internal/model"
 	"codename-rl/internal/pkg/utils"
+	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository[T any] struct {
@@ -53,96 +57,297 @@ func (r *Repository[T]) ExistsById(db *gorm.DB, id any) (bool, error) {
 	return exists, err
 }
 
-func (r *Repository[T]) FindAll(db *gorm.DB, result *[]T, q *model.Query) (int64, error) {
-	tx := db.Model(new(T))
+func (r *Repository[T]) FindAll(db *gorm.DB, result *[]T, q *model.Query) (*model.PageInfo, error) {
+	return findAll(db.Model(new(T)), result, q)
+}
+
+// queryable is implemented by entities that can be listed with findAll
+type queryable interface {
+	TableName() string
+	QueryFields() entity.QueryFields
+}
+
+// QueryFieldError reports a query that references a field or relation the
+// entity does not whitelist.
+type QueryFieldError struct {
+	Kind  string
+	Field string
+}
+
+func (e *QueryFieldError) Error() string {
+	return fmt.Sprintf("%s field %q is not allowed", e.Kind, e.Field)
+}
+
+// checkQuery rejects q if it references anything outside fields
+func checkQuery(fields entity.QueryFields, q *model.Query) error {
+	for _, rel := range q.Preload {
+		if !fields.AllowsPreload(rel) {
+			return &QueryFieldError{Kind: "preload", Field: rel}
+		}
+	}
+	for field := range q.Search {
+		if !fields.AllowsFilter(field) {
+			return &QueryFieldError{Kind: "filter", Field: field}
+		}
+	}
+	switch q.SearchMode {
+	case "", model.SearchModeLike:
+	case model.SearchModeFuzzy:
+		for field := range q.Search {
+			if !fields.AllowsFuzzy(field) {
+				return &QueryFieldError{Kind: "fuzzy search", Field: field}
+			}
+		}
+		// Fuzzy results are ordered by similarity, which no cursor can follow
+		if len(q.Search) == 0 || len(querySort(q)) > 0 || q.Cursor != "" {
+			return &FilterError{Reason: "fuzzy search needs search fields and takes no sort or cursor"}
+		}
+	default:
+		return &FilterError{Reason: fmt.Sprintf("unknown search mode %q", q.SearchMode)}
+	}
+	for field := range q.DateRanges {
+		if !fields.AllowsDateRange(field) {
+			return &QueryFieldError{Kind: "date range", Field: field}
+		}
+	}
+	return checkSort(fields, q)
+}
+
+// findAll applies q to tx, which already selects the model and any scopes,
+// and describes the page it found: the total before pagination unless q skips
+// it, and cursors to the pages either side. Only fields the entity whitelists
+// are accepted, and they are qualified with its table before use.
+func findAll[T any](tx *gorm.DB, result *[]T, q *model.Query) (*model.PageInfo, error) {
+	e, ok := any(new(T)).(queryable)
+	if !ok {
+		return nil, &QueryFieldError{Kind: "query", Field: reflect.TypeFor[T]().Name()}
+	}
+	fields := e.QueryFields()
+	table := e.TableName()
+	if err := checkQuery(fields, q); err != nil {
+		return nil, err
+	}
+
+	if err := checkCollation(tx, q); err != nil {
+		return nil, err
+	}
+
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(e); err != nil {
+		return nil, err
+	}
+	set, err := buildFieldset(stmt.Schema, e, q)
+	if err != nil {
+		return nil, err
+	}
 
 	// -----------------------------------
 	// 0. PRELOAD RELATIONS
 	// -----------------------------------
 	for _, rel := range q.Preload {
-		tx = tx.Preload(rel)
+		if columns, ok := set.preload[rel]; ok {
+			tx = tx.Preload(rel, func(db *gorm.DB) *gorm.DB {
+				return db.Select(columns)
+			})
+		} else {
+			tx = tx.Preload(rel)
+		}
+	}
+
+	tx, err = whereQuery(tx, table, fields, q)
+	if err != nil {
+		return nil, err
 	}
+	fuzzy := q.SearchMode == model.SearchModeFuzzy
 
 	// -----------------------------------
-	// 1. SEARCH MAP (field → value)
+	// 3. COUNT TOTAL
 	// -----------------------------------
-	if len(q.Search) > 0 {
-		for field, value := range q.Search {
-			var condition string
-			var arg interface{}
-
-			if field == "id" {
-				// Exact match
-				condition = field + " = ?"
-				arg = value
-			} else if strings.HasSuffix(field, "_id") {
-				// Exact match for any *_id field
-				condition = field + " = ?"
-				arg = value
-			} else {
-				// LIKE search
-				condition = field + " LIKE ?"
-				arg = "%" + value + "%"
-			}
+	page := new(model.PageInfo)
+	if !q.SkipTotal {
+		var total int64
+		if err := tx.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
 
-			if q.Or {
-				tx = tx.Or(condition, arg)
-			} else {
-				tx = tx.Where(condition, arg)
-			}
+	// Sparse fieldsets are selected after counting, which needs its own select
+	if set.root != nil {
+		columns := make([]string, 0, len(set.root))
+		for _, column := range set.root {
+			columns = append(columns, table+"."+column)
 		}
+		tx = tx.Select(columns)
 	}
 
 	// -----------------------------------
-	// 2. DATE RANGE
+	// 4. SORTING
 	// -----------------------------------
-	for field, dr := range q.DateRanges {
+	// Rows are ordered by the sort keys and then id, so every row has a
+	// unique position for keyset cursors.
+	keys := sortKeys(stmt.Schema, table, q)
+	signature := sortSignature(keys, q.Collation)
 
-		// ---- FROM ----
-		if dr.From != "" {
-			fromTime, err := utils.ParseFlexibleTime(dr.From)
-			if err == nil {
-				tx = tx.Where(field+" >= ?", fromTime)
-			}
+	backward := false
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
 		}
+		if c.Sort != signature || len(c.Values) != len(keys)-1 {
+			return nil, ErrInvalidCursor
+		}
+		backward = c.Prev
+		tx = tx.Where(keysetAfter(keys, append(c.Values, c.ID), backward))
+	}
 
-		// ---- TO ----
-		if dr.To != "" {
-			toTime, err := utils.ParseFlexibleTime(dr.To)
-			if err == nil {
-				tx = tx.Where(field+" <= ?", toTime)
-			}
+	// Paging backwards scans in reverse and flips the rows afterwards
+	if fuzzy {
+		var similarities []string
+		var similarityArgs []interface{}
+		for field, value := range q.Search {
+			similarities = append(similarities, "similarity("+table+"."+field+", ?)")
+			similarityArgs = append(similarityArgs, value)
 		}
+		tx = tx.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "GREATEST(" + strings.Join(similarities, ", ") + ") DESC",
+			Vars: similarityArgs,
+		}})
+	}
+	for _, k := range keys {
+		tx = tx.Order(k.orderBy(backward))
 	}
 
 	// -----------------------------------
-	// 3. COUNT TOTAL
+	// 5. PAGINATION
 	// -----------------------------------
-	var total int64
-	if err := tx.Count(&total).Error; err != nil {
-		return 0, err
+	// One extra row tells whether another page follows
+	if q.Limit > 0 {
+		tx = tx.Limit(q.Limit + 1)
+	}
+	if q.Offset > 0 && q.Cursor == "" {
+		tx = tx.Offset(q.Offset)
+	}
+
+	tx = tx.Find(result)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	more := q.Limit > 0 && len(*result) > q.Limit
+	if more {
+		*result = (*result)[:q.Limit]
+	}
+	if backward {
+		slices.Reverse(*result)
 	}
 
 	// -----------------------------------
-	// 4. SORTING
+	// 6. CURSORS
 	// -----------------------------------
-	if q.SortBy != "" {
-		order := "ASC"
-		if strings.ToUpper(q.Order) == "DESC" {
-			order = "DESC"
+	rows := *result
+	if len(rows) == 0 || fuzzy {
+		return page, nil
+	}
+	if more || backward {
+		if c := cursorAt(tx, reflect.ValueOf(&rows[len(rows)-1]).Elem(), keys, signature, false); c != nil {
+			page.NextCursor = c.encode()
+		}
+	}
+	if (backward && more) || (!backward && (q.Cursor != "" || q.Offset > 0)) {
+		if c := cursorAt(tx, reflect.ValueOf(&rows[0]).Elem(), keys, signature, true); c != nil {
+			page.PrevCursor = c.encode()
 		}
-		tx = tx.Order(q.SortBy + " " + order)
 	}
 
+	return page, nil
+}
+
+// matching applies q's conditions to tx, which already selects the model and
+// any scopes, leaving sorting, paging and fields to the caller.
+func matching[T any](tx *gorm.DB, q *model.Query) (*gorm.DB, error) {
+	e, ok := any(new(T)).(queryable)
+	if !ok {
+		return nil, &QueryFieldError{Kind: "query", Field: reflect.TypeFor[T]().Name()}
+	}
+	fields := e.QueryFields()
+	if err := checkQuery(fields, q); err != nil {
+		return nil, err
+	}
+	return whereQuery(tx, e.TableName(), fields, q)
+}
+
+// whereQuery narrows tx to the rows q matches on table: its search map,
+// filter expression, relation filters and date ranges. q must have passed
+// checkQuery against fields.
+func whereQuery(tx *gorm.DB, table string, fields entity.QueryFields, q *model.Query) (*gorm.DB, error) {
 	// -----------------------------------
-	// 5. PAGINATION
+	// 1. SEARCH MAP (field → value)
 	// -----------------------------------
-	if q.Limit > 0 {
-		tx = tx.Limit(q.Limit)
+	fuzzy := q.SearchMode == model.SearchModeFuzzy
+	for field, value := range q.Search {
+		column := table + "." + field
+
+		if fuzzy {
+			// Trigram match, served by the gin_trgm_ops index
+			tx = tx.Where(column+" % ?", value)
+		} else if field == "id" || strings.HasSuffix(field, "_id") {
+			// Exact match for id and any *_id field
+			tx = tx.Where(column+" = ?", value)
+		} else {
+			// LIKE search
+			tx = tx.Where(column+" LIKE ?", "%"+escapeLike(value)+"%")
+		}
 	}
-	if q.Offset > 0 {
-		tx = tx.Offset(q.Offset)
+
+	// -----------------------------------
+	// 1b. FILTER EXPRESSION
+	// -----------------------------------
+	if q.Filter != nil {
+		builder := &filterBuilder{table: table, fields: fields}
+		expr, err := builder.build(q.Filter, 1)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where(expr)
+	}
+
+	// -----------------------------------
+	// 1c. RELATION FILTERS
+	// -----------------------------------
+	if len(q.Relations) > maxRelationFilters {
+		return nil, &FilterError{Reason: fmt.Sprintf("more than %d relation filters", maxRelationFilters)}
+	}
+	for i := range q.Relations {
+		expr, err := relationExpr(table, fields, &q.Relations[i])
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where(expr)
+	}
+
+	// -----------------------------------
+	// 2. DATE RANGE
+	// -----------------------------------
+	for field, dr := range q.DateRanges {
+
+		// ---- FROM ----
+		if dr.From != "" {
+			fromTime, err := utils.ParseFlexibleTime(dr.From)
+			if err == nil {
+				tx = tx.Where(table+"."+field+" >= ?", fromTime)
+			}
+		}
+
+		// ---- TO ----
+		if dr.To != "" {
+			toTime, err := utils.ParseFlexibleTime(dr.To)
+			if err == nil {
+				tx = tx.Where(table+"."+field+" <= ?", toTime)
+			}
+		}
 	}
 
-	return total, tx.Find(result).Error
+	return tx, nil
 }
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// searchHeadline marks matches in snippets and keeps them short
+const searchHeadline = "StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5, MaxFragments=2"
+
+// escapedBody is the snippet source with HTML escaped, so the <mark> tags are
+// the only markup in a snippet, whatever a stored name or description holds
+const escapedBody = "replace(replace(replace(body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+
+type SearchRepository struct {
+	Log *logrus.Logger
+}
+
+func NewSearchRepository(log *logrus.Logger) *SearchRepository {
+	return &SearchRepository{
+		Log: log,
+	}
+}
+
+// searchSource is one table a search runs over
+type searchSource struct {
+	table  string
+	owner  OwnerScope
+	fields string // type, id, person_id, title and the text snippets are cut from
+}
+
+var searchSources = map[string]searchSource{
+	entity.SearchHitPerson: {
+		table: "persons",
+		owner: inMemberBook("persons"),
+		fields: "'person' AS type, persons.id, persons.id AS person_id, " +
+			"concat_ws(' ', persons.first_name, persons.last_name) AS title, " +
+			"concat_ws(' ', persons.first_name, persons.last_name, persons.nickname, persons.description) AS body",
+	},
+	entity.SearchHitPhone: {
+		table: "phones",
+		owner: throughMemberPerson("phones"),
+		fields: "'phone' AS type, phones.id, phones.person_id, phones.number AS title, " +
+			"concat_ws(' ', phones.name, phones.number) AS body",
+	},
+	entity.SearchHitTag: {
+		table:  "tags",
+		owner:  inMemberBook("tags"),
+		fields: "'tag' AS type, tags.id, '' AS person_id, tags.name AS title, tags.name AS body",
+	},
+	entity.SearchHitImportantDate: {
+		table: "important_dates",
+		owner: throughMemberPerson("important_dates"),
+		fields: "'important_date' AS type, important_dates.id, important_dates.person_id, " +
+			"important_dates.name AS title, important_dates.name AS body",
+	},
+}
+
+// Search ranks the rows of the given hit types that match text across the
+// books userID is a member of. Snippets are only cut for the returned hits.
+func (r *SearchRepository) Search(ctx context.Context, db *gorm.DB, hits *[]entity.SearchHit, text string, types []string, userID string, limit int) error {
+	query := prefixQuery(text)
+	if query == "" {
+		return nil
+	}
+
+	var parts []string
+	var subqueries []interface{}
+	for _, hitType := range types {
+		source, ok := searchSources[hitType]
+		if !ok {
+			continue
+		}
+		subquery := db.Session(&gorm.Session{NewDB: true}).
+			Table(source.table).
+			Select(source.fields+", ts_rank(search_vector, to_tsquery('simple', ?)) AS rank", query).
+			Where("search_vector @@ to_tsquery('simple', ?)", query).
+			Scopes(source.owner(userID))
+		parts = append(parts, "(?)")
+		subqueries = append(subqueries, subquery)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+
+	args := append([]interface{}{query}, subqueries...)
+	args = append(args, limit)
+	return db.WithContext(ctx).Raw("SELECT type, id, person_id, title, rank, "+
+		"ts_headline('simple', "+escapedBody+", to_tsquery('simple', ?), '"+searchHeadline+"') AS snippet "+
+		"FROM ("+strings.Join(parts, " UNION ALL ")+") AS hits "+
+		"ORDER BY rank DESC, title ASC LIMIT ?", args...).
+		Scan(hits).Error
+}
+
+// prefixQuery turns free text into a tsquery that matches every word as a
+// prefix, so "jo sm" finds "John Smith". Anything but letters and digits is
+// dropped, which keeps user input from breaking the tsquery syntax.
+func prefixQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = strings.ToLower(word) + ":*"
+	}
+	return strings.Join(words, " & ")
+}
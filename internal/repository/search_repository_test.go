@@ -0,0 +1,26 @@
+package repository
+
+import "testing"
+
+func TestPrefixQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "empty", text: "", want: ""},
+		{name: "punctuation only", text: " &|!:*() ", want: ""},
+		{name: "one word", text: "Ann", want: "ann:*"},
+		{name: "several words", text: "  ann  Lee ", want: "ann:* & lee:*"},
+		{name: "tsquery operators are dropped", text: "ann & !lee | (bob):*", want: "ann:* & lee:* & bob:*"},
+		{name: "quotes and digits", text: "o'neil 42", want: "o:* & neil:* & 42:*"},
+		{name: "unicode letters", text: "Ñandú Łukasz", want: "ñandú:* & łukasz:*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prefixQuery(tt.text); got != tt.want {
+				t.Fatalf("prefixQuery(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
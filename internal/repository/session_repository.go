@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	Repository[entity.Session]
+	Log *logrus.Logger
+}
+
+func NewSessionRepository(log *logrus.Logger) *SessionRepository {
+	return &SessionRepository{
+		Log: log,
+	}
+}
+
+// FindActive loads a session that is neither revoked nor expired
+func (r *SessionRepository) FindActive(ctx context.Context, db *gorm.DB, session *entity.Session, id string) error {
+	return db.WithContext(ctx).
+		Where("id = ? AND revoked_at = 0 AND expires_at > ?", id, time.Now().UnixMilli()).
+		Take(session).Error
+}
+
+func (r *SessionRepository) RevokeByID(ctx context.Context, db *gorm.DB, id string) error {
+	return db.WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("id = ? AND revoked_at = 0", id).
+		Update("revoked_at", time.Now().UnixMilli()).
+		Error
+}
+
+func (r *SessionRepository) RevokeByUserID(ctx context.Context, db *gorm.DB, userID string) error {
+	return db.WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("user_id = ? AND revoked_at = 0", userID).
+		Update("revoked_at", time.Now().UnixMilli()).
+		Error
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SmartListRepository struct {
+	Repository[entity.SmartList]
+	Log *logrus.Logger
+}
+
+func NewSmartListRepository(log *logrus.Logger) *SmartListRepository {
+	return &SmartListRepository{
+		Log: log,
+	}
+}
+
+func (r *SmartListRepository) FindByIDAndUserID(ctx context.Context, db *gorm.DB, list *entity.SmartList, id string, userID string) error {
+	return db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Take(list).Error
+}
+
+// FindAllByUserID lists userID's smart lists, pinned ones first in dashboard order
+func (r *SmartListRepository) FindAllByUserID(ctx context.Context, db *gorm.DB, lists *[]entity.SmartList, userID string, pinnedOnly bool) error {
+	query := db.WithContext(ctx).Where("user_id = ?", userID)
+	if pinnedOnly {
+		query = query.Where("pinned")
+	}
+	return query.Order("pinned DESC, position ASC, name ASC").Find(lists).Error
+}
+
+func (r *SmartListRepository) ExistsByName(ctx context.Context, db *gorm.DB, name string, userID string, excludeID string) (bool, error) {
+	var exists bool
+	err := db.WithContext(ctx).
+		Model(&entity.SmartList{}).
+		Select("count(*) > 0").
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).
+		Find(&exists).Error
+	return exists, err
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const maxSortKeys = 5
+
+// collationName keeps a collation safe to quote into ORDER BY
+var collationName = regexp.MustCompile(`^[A-Za-z0-9_.@-]+$`)
+
+// SortError reports a sort key or collation that cannot be applied
+type SortError struct {
+	Field  string
+	Reason string
+}
+
+func (e *SortError) Error() string {
+	if e.Field == "" {
+		return "invalid sort: " + e.Reason
+	}
+	return fmt.Sprintf("invalid sort on %q: %s", e.Field, e.Reason)
+}
+
+// sortKey is one term of a list's ORDER BY. Text keys treat empty strings as
+// null, so blank names sort with the missing ones.
+type sortKey struct {
+	field      string
+	expr       string // what rows are ordered and compared by
+	text       bool
+	nullable   bool
+	desc       bool
+	nullsFirst bool
+}
+
+// querySort returns q's sort keys, falling back to the single SortBy and Order
+func querySort(q *model.Query) []model.SortKey {
+	if len(q.Sort) > 0 {
+		return q.Sort
+	}
+	if q.SortBy != "" {
+		return []model.SortKey{{Field: q.SortBy, Order: q.Order}}
+	}
+	return nil
+}
+
+// checkSort rejects sort keys outside fields, repeated or malformed ones
+func checkSort(fields entity.QueryFields, q *model.Query) error {
+	keys := querySort(q)
+	if len(keys) > maxSortKeys {
+		return &SortError{Reason: fmt.Sprintf("more than %d sort keys", maxSortKeys)}
+	}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if !fields.AllowsSort(key.Field) {
+			return &QueryFieldError{Kind: "sort", Field: key.Field}
+		}
+		if seen[key.Field] {
+			return &SortError{Field: key.Field, Reason: "sorted more than once"}
+		}
+		seen[key.Field] = true
+		switch strings.ToLower(key.Order) {
+		case "", "asc", "desc":
+		default:
+			return &SortError{Field: key.Field, Reason: fmt.Sprintf("unknown order %q", key.Order)}
+		}
+		switch strings.ToLower(key.Nulls) {
+		case "", "first", "last":
+		default:
+			return &SortError{Field: key.Field, Reason: fmt.Sprintf("unknown nulls position %q", key.Nulls)}
+		}
+	}
+	if q.Collation != "" && !collationName.MatchString(q.Collation) {
+		return &SortError{Reason: fmt.Sprintf("invalid collation %q", q.Collation)}
+	}
+	return nil
+}
+
+// checkCollation asks the database whether q's collation exists, so a typo is
+// a bad request rather than a failed query.
+func checkCollation(tx *gorm.DB, q *model.Query) error {
+	if q.Collation == "" {
+		return nil
+	}
+	var known bool
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Raw("SELECT EXISTS (SELECT 1 FROM pg_collation WHERE collname = ?)", q.Collation).
+		Scan(&known).Error
+	if err != nil {
+		return err
+	}
+	if !known {
+		return &SortError{Reason: fmt.Sprintf("unknown collation %q", q.Collation)}
+	}
+	return nil
+}
+
+// sortKeys resolves q's sort on table, always ending with id so every row has
+// a unique position for keyset cursors. Nulls come last ascending and first
+// descending unless a key says otherwise, as Postgres does by default.
+func sortKeys(s *schema.Schema, table string, q *model.Query) []sortKey {
+	var keys []sortKey
+	desc := strings.EqualFold(q.Order, "desc")
+	for _, key := range querySort(q) {
+		k := sortKey{field: key.Field, expr: table + "." + key.Field, nullable: true}
+		k.desc = strings.EqualFold(key.Order, "desc")
+		k.nullsFirst = k.desc
+		if key.Nulls != "" {
+			k.nullsFirst = strings.EqualFold(key.Nulls, "first")
+		}
+		if field := s.LookUpField(key.Field); field != nil && field.DataType == schema.String {
+			k.text = true
+			k.expr = "NULLIF(" + k.expr + ", '')"
+			if q.Collation != "" {
+				k.expr += ` COLLATE "` + q.Collation + `"`
+			}
+		}
+		keys = append(keys, k)
+		desc = k.desc
+	}
+	// The id tiebreak follows the last key's direction
+	return append(keys, sortKey{field: "id", expr: table + ".id", desc: desc})
+}
+
+// sortSignature identifies a sort, so a cursor is only followed by the sort
+// it was issued for.
+func sortSignature(keys []sortKey, collation string) string {
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		part := k.field + " asc"
+		if k.desc {
+			part = k.field + " desc"
+		}
+		if k.nullsFirst {
+			part += " first"
+		}
+		parts = append(parts, part)
+	}
+	signature := strings.Join(parts, ",")
+	if collation != "" {
+		signature += " @" + collation
+	}
+	return signature
+}
+
+// orderBy is k's ORDER BY term, reversed when paging backwards
+func (k sortKey) orderBy(backward bool) string {
+	desc, nullsFirst := k.desc != backward, k.nullsFirst != backward
+	term := k.expr + " ASC"
+	if desc {
+		term = k.expr + " DESC"
+	}
+	if !k.nullable {
+		return term
+	}
+	if nullsFirst {
+		return term + " NULLS FIRST"
+	}
+	return term + " NULLS LAST"
+}
+
+// keysetAfter matches the rows that follow values in the order of keys, or
+// precede them when paging backwards: the first key that differs decides.
+func keysetAfter(keys []sortKey, values []any, backward bool) clause.Expression {
+	var rows []clause.Expression
+	for i, k := range keys {
+		terms := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, clause.Expr{SQL: keys[j].expr + " IS NOT DISTINCT FROM ?", Vars: []interface{}{values[j]}})
+		}
+
+		desc, nullsFirst := k.desc != backward, k.nullsFirst != backward
+		if values[i] == nil {
+			// Only non-null values follow nulls that sort first
+			if !nullsFirst {
+				continue
+			}
+			terms = append(terms, clause.Expr{SQL: k.expr + " IS NOT NULL"})
+		} else {
+			op := ">"
+			if desc {
+				op = "<"
+			}
+			sql := k.expr + " " + op + " ?"
+			if k.nullable && !nullsFirst {
+				sql = "(" + sql + " OR " + k.expr + " IS NULL)"
+			}
+			terms = append(terms, clause.Expr{SQL: sql, Vars: []interface{}{values[i]}})
+		}
+		rows = append(rows, clause.And(terms...))
+	}
+	return clause.Expr{SQL: "(?)", Vars: []interface{}{clause.Or(rows...)}}
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+func personSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	s, err := schema.Parse(&entity.Person{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("parse person schema: %v", err)
+	}
+	return s
+}
+
+func TestCheckSort(t *testing.T) {
+	fields := (&entity.Person{}).QueryFields()
+	key := func(field, order, nulls string) model.SortKey {
+		return model.SortKey{Field: field, Order: order, Nulls: nulls}
+	}
+
+	tests := []struct {
+		name     string
+		query    model.Query
+		fieldErr bool
+		sortErr  bool
+	}{
+		{name: "no sort", query: model.Query{}},
+		{name: "legacy sort by", query: model.Query{SortBy: "last_name", Order: "DESC"}},
+		{name: "keys with nulls", query: model.Query{Sort: []model.SortKey{key("last_name", "asc", "first"), key("created_at", "desc", "last")}}},
+		{name: "collation", query: model.Query{Sort: []model.SortKey{key("last_name", "", "")}, Collation: "de-DE-x-icu"}},
+		{name: "field outside the whitelist", query: model.Query{Sort: []model.SortKey{key("password", "", "")}}, fieldErr: true},
+		{name: "legacy field outside the whitelist", query: model.Query{SortBy: "password"}, fieldErr: true},
+		{name: "field sorted twice", query: model.Query{Sort: []model.SortKey{key("last_name", "", ""), key("last_name", "desc", "")}}, sortErr: true},
+		{name: "unknown order", query: model.Query{Sort: []model.SortKey{key("last_name", "up", "")}}, sortErr: true},
+		{name: "unknown nulls", query: model.Query{Sort: []model.SortKey{key("last_name", "", "middle")}}, sortErr: true},
+		{name: "collation that could break out of quotes", query: model.Query{Collation: `C" ; DROP TABLE persons; --`}, sortErr: true},
+		{
+			name: "too many keys",
+			query: model.Query{Sort: []model.SortKey{
+				key("first_name", "", ""), key("last_name", "", ""), key("nickname", "", ""),
+				key("created_at", "", ""), key("updated_at", "", ""), key("first_name", "desc", ""),
+			}},
+			sortErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSort(fields, &tt.query)
+			var fieldErr *QueryFieldError
+			var sortErr *SortError
+			switch {
+			case tt.fieldErr:
+				if !errors.As(err, &fieldErr) {
+					t.Fatalf("err = %v, want a QueryFieldError", err)
+				}
+			case tt.sortErr:
+				if !errors.As(err, &sortErr) {
+					t.Fatalf("err = %v, want a SortError", err)
+				}
+			case err != nil:
+				t.Fatalf("err = %v, want none", err)
+			}
+		})
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	s := personSchema(t)
+
+	t.Run("no sort orders by id alone", func(t *testing.T) {
+		keys := sortKeys(s, "persons", &model.Query{})
+		want := []sortKey{{field: "id", expr: "persons.id"}}
+		if !reflect.DeepEqual(keys, want) {
+			t.Fatalf("keys = %+v, want %+v", keys, want)
+		}
+	})
+
+	t.Run("text keys treat empty as null and take the collation", func(t *testing.T) {
+		keys := sortKeys(s, "persons", &model.Query{
+			Sort:      []model.SortKey{{Field: "last_name", Order: "asc"}, {Field: "created_at", Order: "desc"}},
+			Collation: "de-DE-x-icu",
+		})
+		want := []sortKey{
+			{field: "last_name", expr: `NULLIF(persons.last_name, '') COLLATE "de-DE-x-icu"`, text: true, nullable: true},
+			{field: "created_at", expr: "persons.created_at", nullable: true, desc: true, nullsFirst: true},
+			{field: "id", expr: "persons.id", desc: true},
+		}
+		if !reflect.DeepEqual(keys, want) {
+			t.Fatalf("keys = %+v\nwant %+v", keys, want)
+		}
+	})
+
+	t.Run("explicit nulls override the default", func(t *testing.T) {
+		keys := sortKeys(s, "persons", &model.Query{
+			Sort: []model.SortKey{{Field: "nickname", Order: "desc", Nulls: "last"}, {Field: "first_name", Nulls: "first"}},
+		})
+		if keys[0].nullsFirst || !keys[0].desc {
+			t.Errorf("nickname key = %+v, want descending with nulls last", keys[0])
+		}
+		if !keys[1].nullsFirst || keys[1].desc {
+			t.Errorf("first_name key = %+v, want ascending with nulls first", keys[1])
+		}
+		if keys[2].desc {
+			t.Errorf("id key = %+v, want it to follow the last key ascending", keys[2])
+		}
+	})
+
+	t.Run("legacy sort by", func(t *testing.T) {
+		keys := sortKeys(s, "persons", &model.Query{SortBy: "first_name", Order: "desc"})
+		if len(keys) != 2 || keys[0].field != "first_name" || !keys[0].desc || !keys[1].desc {
+			t.Fatalf("keys = %+v, want first_name then id, both descending", keys)
+		}
+	})
+}
+
+func TestSortSignature(t *testing.T) {
+	keys := []sortKey{
+		{field: "last_name", desc: true, nullsFirst: true},
+		{field: "first_name"},
+		{field: "id", desc: true},
+	}
+	tests := []struct {
+		name      string
+		keys      []sortKey
+		collation string
+		want      string
+	}{
+		{name: "id only", keys: []sortKey{{field: "id"}}, want: "id asc"},
+		{name: "keys in order", keys: keys, want: "last_name desc first,first_name asc,id desc"},
+		{name: "collation", keys: keys, collation: "C", want: "last_name desc first,first_name asc,id desc @C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortSignature(tt.keys, tt.collation); got != tt.want {
+				t.Fatalf("signature = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// A cursor of one sort must not be taken for another
+	flipped := []sortKey{{field: "last_name", desc: true}, {field: "first_name"}, {field: "id", desc: true}}
+	if sortSignature(keys, "") == sortSignature(flipped, "") {
+		t.Error("sorts differing only in nulls position share a signature")
+	}
+}
+
+func TestSortKeyOrderBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      sortKey
+		backward bool
+		want     string
+	}{
+		{name: "ascending", key: sortKey{expr: "persons.first_name", nullable: true}, want: "persons.first_name ASC NULLS LAST"},
+		{name: "descending", key: sortKey{expr: "persons.first_name", nullable: true, desc: true, nullsFirst: true}, want: "persons.first_name DESC NULLS FIRST"},
+		{name: "ascending backwards", key: sortKey{expr: "persons.first_name", nullable: true}, backward: true, want: "persons.first_name DESC NULLS FIRST"},
+		{name: "descending backwards", key: sortKey{expr: "persons.first_name", nullable: true, desc: true, nullsFirst: true}, backward: true, want: "persons.first_name ASC NULLS LAST"},
+		{name: "id takes no nulls clause", key: sortKey{expr: "persons.id"}, want: "persons.id ASC"},
+		{name: "id backwards", key: sortKey{expr: "persons.id", desc: true}, backward: true, want: "persons.id ASC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.orderBy(tt.backward); got != tt.want {
+				t.Fatalf("orderBy = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetAfter(t *testing.T) {
+	name := sortKey{field: "last_name", expr: "persons.last_name", text: true, nullable: true}
+	id := sortKey{field: "id", expr: "persons.id"}
+	created := sortKey{field: "created_at", expr: "persons.created_at", nullable: true, desc: true, nullsFirst: true}
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		keys     []sortKey
+		values   []any
+		backward bool
+		sql      string
+		vars     []interface{}
+	}{
+		{
+			name:   "id only",
+			keys:   []sortKey{id},
+			values: []any{"p1"},
+			sql:    "(persons.id > $1)",
+			vars:   []interface{}{"p1"},
+		},
+		{
+			name:   "nulls last follow a value",
+			keys:   []sortKey{name, id},
+			values: []any{"Lee", "p1"},
+			sql:    "((((persons.last_name > $1 OR persons.last_name IS NULL)) OR (persons.last_name IS NOT DISTINCT FROM $2 AND persons.id > $3)))",
+			vars:   []interface{}{"Lee", "Lee", "p1"},
+		},
+		{
+			name:   "nothing but ties follows a null sorted last",
+			keys:   []sortKey{name, id},
+			values: []any{nil, "p1"},
+			sql:    "((persons.last_name IS NOT DISTINCT FROM $1 AND persons.id > $2))",
+			vars:   []interface{}{nil, "p1"},
+		},
+		{
+			name:     "backwards a null sorted last is preceded by every value",
+			keys:     []sortKey{name, id},
+			values:   []any{nil, "p1"},
+			backward: true,
+			sql:      "((persons.last_name IS NOT NULL OR (persons.last_name IS NOT DISTINCT FROM $1 AND persons.id < $2)))",
+			vars:     []interface{}{nil, "p1"},
+		},
+		{
+			name:     "backwards a value is preceded by smaller values only",
+			keys:     []sortKey{name, id},
+			values:   []any{"Lee", "p1"},
+			backward: true,
+			sql:      "((persons.last_name < $1 OR (persons.last_name IS NOT DISTINCT FROM $2 AND persons.id < $3)))",
+			vars:     []interface{}{"Lee", "Lee", "p1"},
+		},
+		{
+			name:   "descending with nulls first",
+			keys:   []sortKey{created, {field: "id", expr: "persons.id", desc: true}},
+			values: []any{at, "p1"},
+			sql:    "((persons.created_at < $1 OR (persons.created_at IS NOT DISTINCT FROM $2 AND persons.id < $3)))",
+			vars:   []interface{}{at, at, "p1"},
+		},
+		{
+			name:   "values follow a null sorted first",
+			keys:   []sortKey{created, {field: "id", expr: "persons.id", desc: true}},
+			values: []any{nil, "p1"},
+			sql:    "((persons.created_at IS NOT NULL OR (persons.created_at IS NOT DISTINCT FROM $1 AND persons.id < $2)))",
+			vars:   []interface{}{nil, "p1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, vars := renderSQL(t, keysetAfter(tt.keys, tt.values, tt.backward))
+			if sql != tt.sql {
+				t.Errorf("sql = %s\nwant  %s", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(vars, tt.vars) {
+				t.Errorf("vars = %#v, want %#v", vars, tt.vars)
+			}
+		})
+	}
+}
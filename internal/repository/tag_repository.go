@@ -2,28 +2,30 @@ package repository
 
 import (
 	"codename-rl/internal/entity"
-	"fmt"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type TagRepository struct {
-	Repository[entity.Tag]
+	OwnedRepository[entity.Tag]
 	Log *logrus.Logger
 }
 
 func NewTagRepository(log *logrus.Logger) *TagRepository {
 	return &TagRepository{
-		Log: log,
+		OwnedRepository: OwnedRepository[entity.Tag]{Owner: inMemberBook("tags")},
+		Log:             log,
 	}
 }
 
-func (r *TagRepository) ExistsByName(tx *gorm.DB, tag *entity.Tag, name string) (bool, error) {
+// ExistsByName reports whether the address book has another tag called name; excludeID
+// skips the tag being renamed.
+func (r *TagRepository) ExistsByName(tx *gorm.DB, name string, bookID string, excludeID string) (bool, error) {
 	var exists bool
-	err := tx.Model(tag).
+	err := tx.Model(&entity.Tag{}).
 		Select("count(*) > 0").
-		Where("name = ?", name).
+		Where("address_book_id = ? AND name = ? AND id <> ?", bookID, name, excludeID).
 		Find(&exists).Error
 	return exists, err
 }
@@ -40,25 +42,13 @@ func (r *TagRepository) Create(tx *gorm.DB, tag *entity.Tag, personIDs []string)
 		return nil
 	}
 
-	// 2. Validate that all PersonIDs actually exist
-	var count int64
-	if err := tx.Model(&entity.Person{}).
-		Where("id IN ?", personIDs).
-		Count(&count).Error; err != nil {
+	// 2. Validate that all PersonIDs exist in the same address book
+	persons, err := personsInBook(tx, personIDs, tag.AddressBookID)
+	if err != nil {
 		return err
 	}
 
-	if count != int64(len(personIDs)) {
-		return fmt.Errorf("one or more person IDs do not exist")
-	}
-
-	// 3. Prepare dummy Person objects (only IDs needed)
-	persons := make([]entity.Person, 0, len(personIDs))
-	for _, id := range personIDs {
-		persons = append(persons, entity.Person{ID: id})
-	}
-
-	// 4. Save relations
+	// 3. Save relations
 	if err := tx.Model(tag).Association("Persons").Append(persons); err != nil {
 		return err
 	}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"codename-rl/internal/entity"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type TwoFactorChallengeRepository struct {
+	Repository[entity.TwoFactorChallenge]
+	Log *logrus.Logger
+}
+
+func NewTwoFactorChallengeRepository(log *logrus.Logger) *TwoFactorChallengeRepository {
+	return &TwoFactorChallengeRepository{
+		Log: log,
+	}
+}
+
+func (r *TwoFactorChallengeRepository) FindByHash(ctx context.Context, db *gorm.DB, challenge *entity.TwoFactorChallenge, hash string) error {
+	return db.WithContext(ctx).Where("token_hash = ?", hash).Take(challenge).Error
+}
+
+// ConsumeAttempt counts one code attempt against the challenge and reports
+// false once maxAttempts have been used. The row stays locked until commit.
+func (r *TwoFactorChallengeRepository) ConsumeAttempt(ctx context.Context, db *gorm.DB, id string, maxAttempts int) (bool, error) {
+	result := db.WithContext(ctx).
+		Model(&entity.TwoFactorChallenge{}).
+		Where("id = ? AND attempts < ?", id, maxAttempts).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *TwoFactorChallengeRepository) DeleteByUserID(ctx context.Context, db *gorm.DB, userID string) error {
+	return db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.TwoFactorChallenge{}).Error
+}
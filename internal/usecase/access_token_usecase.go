@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"codename-rl/internal/model/converter"
+	"codename-rl/internal/pkg/utils"
+	"codename-rl/internal/repository"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// accessTokenTouchInterval limits how often last_used_at is written for a busy token
+const accessTokenTouchInterval = time.Minute
+
+type AccessTokenUseCase struct {
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	AccessTokenRepository *repository.AccessTokenRepository
+}
+
+func NewAccessTokenUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	accessTokenRepository *repository.AccessTokenRepository) *AccessTokenUseCase {
+	return &AccessTokenUseCase{
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		AccessTokenRepository: accessTokenRepository,
+	}
+}
+
+// Create issues a new token; the plain value is only ever returned here
+func (c *AccessTokenUseCase) Create(ctx context.Context, request *model.CreateAccessTokenRequest) (*model.AccessTokenResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	scopes, ok := normalizeScopes(request.Scopes)
+	if !ok {
+		c.Log.Warnf("Invalid access token scopes : %v", request.Scopes)
+		return nil, fiber.ErrBadRequest
+	}
+
+	secret, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		c.Log.Warnf("Failed to generate access token : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	plain := model.AccessTokenPrefix + secret
+
+	token := &entity.AccessToken{
+		ID:        uuid.New().String(),
+		UserID:    request.UserID,
+		Name:      request.Name,
+		TokenHash: utils.HashToken(plain),
+		Scopes:    strings.Join(scopes, ","),
+	}
+	if request.ExpiresInDays > 0 {
+		token.ExpiresAt = time.Now().AddDate(0, 0, request.ExpiresInDays).Unix()
+	}
+
+	if err := c.AccessTokenRepository.Create(tx, token); err != nil {
+		c.Log.Warnf("Failed create access token : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := converter.AccessTokenToResponse(token)
+	response.Token = plain
+	return response, nil
+}
+
+func (c *AccessTokenUseCase) Get(ctx context.Context, request *model.GetAccessTokenRequest) (*[]model.AccessTokenResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	var tokens []entity.AccessToken
+	if err := c.AccessTokenRepository.FindAllByUserID(ctx, tx, &tokens, request.UserID); err != nil {
+		c.Log.Warnf("Failed find access tokens : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AccessTokensToResponses(&tokens), nil
+}
+
+// Delete revokes a token by removing it
+func (c *AccessTokenUseCase) Delete(ctx context.Context, request *model.DeleteAccessTokenRequest) (*model.AccessTokenResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	token := new(entity.AccessToken)
+	if err := c.AccessTokenRepository.FindByIDAndUserID(ctx, tx, token, request.ID, request.UserID); err != nil {
+		c.Log.Warnf("Failed find access token by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if err := c.AccessTokenRepository.Delete(tx, token); err != nil {
+		c.Log.Warnf("Failed delete access token : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AccessTokenToResponse(token), nil
+}
+
+// normalizeScopes checks every scope is "<resource>:read" or "<resource>:write"
+// for a known resource and drops duplicates.
+func normalizeScopes(scopes []string) ([]string, bool) {
+	known := make(map[string]bool, len(model.AccessTokenResources)*2)
+	for _, resource := range model.AccessTokenResources {
+		known[resource+":read"] = true
+		known[resource+":write"] = true
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !known[scope] {
+			return nil, false
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		normalized = append(normalized, scope)
+	}
+	return normalized, true
+}
+
+// verifyAccessToken resolves a personal access token to the owning user and
+// records when it was last used.
+func verifyAccessToken(ctx context.Context, tx *gorm.DB, accessTokenRepository *repository.AccessTokenRepository,
+	plain string) (*entity.AccessToken, error) {
+	token := new(entity.AccessToken)
+	if err := accessTokenRepository.FindByHash(ctx, tx, token, utils.HashToken(plain)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.ExpiresAt != 0 && token.ExpiresAt < now.Unix() {
+		return nil, fiber.ErrUnauthorized
+	}
+
+	if now.Sub(time.Unix(token.LastUsedAt, 0)) >= accessTokenTouchInterval {
+		token.LastUsedAt = now.Unix()
+		if err := accessTokenRepository.TouchLastUsed(ctx, tx, token.ID, token.LastUsedAt); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
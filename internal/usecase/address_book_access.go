@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/repository"
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// personalAddressBookName names the book created for a user's first contact
+const personalAddressBookName = "Personal"
+
+// authorizeAddressBook checks that userID holds at least required in bookID.
+// A book the caller is not a member of is reported as missing.
+func authorizeAddressBook(ctx context.Context, tx *gorm.DB, addressBookRepository *repository.AddressBookRepository,
+	bookID string, userID string, required entity.AddressBookRole) (*entity.AddressBookMember, *fiber.Error) {
+	member := new(entity.AddressBookMember)
+	if err := addressBookRepository.FindMember(ctx, tx, member, bookID, userID); err != nil {
+		return nil, memberError(err)
+	}
+	if !member.Role.Allows(required) {
+		return nil, fiber.ErrForbidden
+	}
+	return member, nil
+}
+
+// authorizePerson is authorizeAddressBook for the book that keeps personID
+func authorizePerson(ctx context.Context, tx *gorm.DB, addressBookRepository *repository.AddressBookRepository,
+	personID string, userID string, required entity.AddressBookRole) (*entity.AddressBookMember, *fiber.Error) {
+	member := new(entity.AddressBookMember)
+	if err := addressBookRepository.FindMemberByPersonID(ctx, tx, member, personID, userID); err != nil {
+		return nil, memberError(err)
+	}
+	if !member.Role.Allows(required) {
+		return nil, fiber.ErrForbidden
+	}
+	return member, nil
+}
+
+// resolveAddressBook authorises bookID, or when it is empty the caller's
+// personal book, which is created the first time it is needed.
+func resolveAddressBook(ctx context.Context, tx *gorm.DB, addressBookRepository *repository.AddressBookRepository,
+	bookID string, userID string, required entity.AddressBookRole) (*entity.AddressBookMember, *fiber.Error) {
+	if bookID != "" {
+		return authorizeAddressBook(ctx, tx, addressBookRepository, bookID, userID, required)
+	}
+
+	member := new(entity.AddressBookMember)
+	err := addressBookRepository.FindDefaultMember(ctx, tx, member, userID)
+	if err == nil {
+		return member, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.ErrInternalServerError
+	}
+
+	book := &entity.AddressBook{
+		ID:   uuid.New().String(),
+		Name: personalAddressBookName,
+	}
+	member, err = addressBookRepository.CreateWithOwner(tx, book, userID)
+	if err != nil {
+		return nil, fiber.ErrInternalServerError
+	}
+	return member, nil
+}
+
+func memberError(err error) *fiber.Error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.ErrNotFound
+	}
+	return fiber.ErrInternalServerError
+}
@@ -0,0 +1,508 @@
+package usecase
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"codename-rl/internal/model/converter"
+	"codename-rl/internal/pkg/email"
+	"codename-rl/internal/pkg/utils"
+	"codename-rl/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// addressBookInvitationTTL is how long an invitation can be accepted
+const addressBookInvitationTTL = 7 * 24 * time.Hour
+
+type AddressBookUseCase struct {
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	AddressBookRepository *repository.AddressBookRepository
+	InvitationRepository  *repository.AddressBookInvitationRepository
+	UserRepository        *repository.UserRepository
+	EmailClient           *email.Client
+}
+
+func NewAddressBookUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	addressBookRepository *repository.AddressBookRepository, invitationRepository *repository.AddressBookInvitationRepository,
+	userRepository *repository.UserRepository, emailClient *email.Client) *AddressBookUseCase {
+	return &AddressBookUseCase{
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		AddressBookRepository: addressBookRepository,
+		InvitationRepository:  invitationRepository,
+		UserRepository:        userRepository,
+		EmailClient:           emailClient,
+	}
+}
+
+func (c *AddressBookUseCase) Create(ctx context.Context, request *model.CreateAddressBookRequest) (*model.AddressBookResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	book := &entity.AddressBook{
+		ID:   uuid.New().String(),
+		Name: request.Name,
+	}
+
+	member, err := c.AddressBookRepository.CreateWithOwner(tx, book, request.UserID)
+	if err != nil {
+		c.Log.Warnf("Failed create address book to database : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AddressBookToResponse(book, member.Role), nil
+}
+
+func (c *AddressBookUseCase) Get(ctx context.Context, request *model.GetAddressBookRequest) (*[]model.AddressBookResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	var members []entity.AddressBookMember
+	if err := c.AddressBookRepository.FindMemberships(ctx, tx, &members, request.UserID); err != nil {
+		c.Log.Warnf("Failed find address books : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.MembershipsToAddressBookResponses(&members), nil
+}
+
+func (c *AddressBookUseCase) Update(ctx context.Context, request *model.UpdateAddressBookRequest) (*model.AddressBookResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	member, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, request.ID, request.UserID, entity.AddressBookRoleOwner)
+	if fiberErr != nil {
+		c.Log.Warnf("User %s may not rename address book %s", request.UserID, request.ID)
+		return nil, fiberErr
+	}
+
+	book := new(entity.AddressBook)
+	if err := c.AddressBookRepository.FindById(tx, book, request.ID); err != nil {
+		c.Log.Warnf("Failed find address book by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	book.Name = request.Name
+	if err := c.AddressBookRepository.Update(tx, book); err != nil {
+		c.Log.Warnf("Failed save address book : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AddressBookToResponse(book, member.Role), nil
+}
+
+// Delete removes an empty book; contacts have to be deleted first
+func (c *AddressBookUseCase) Delete(ctx context.Context, request *model.DeleteAddressBookRequest) (*model.AddressBookResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	member, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, request.ID, request.UserID, entity.AddressBookRoleOwner)
+	if fiberErr != nil {
+		c.Log.Warnf("User %s may not delete address book %s", request.UserID, request.ID)
+		return nil, fiberErr
+	}
+
+	book := new(entity.AddressBook)
+	if err := c.AddressBookRepository.FindById(tx, book, request.ID); err != nil {
+		c.Log.Warnf("Failed find address book by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	count, err := c.AddressBookRepository.CountContents(ctx, tx, book.ID)
+	if err != nil {
+		c.Log.Warnf("Failed count address book contents : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if count > 0 {
+		c.Log.Warnf("Address book %s still has %d entries", book.ID, count)
+		return nil, fiber.ErrConflict
+	}
+
+	if err := c.AddressBookRepository.Delete(tx, book); err != nil {
+		c.Log.Warnf("Failed delete address book : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AddressBookToResponse(book, member.Role), nil
+}
+
+func (c *AddressBookUseCase) GetMembers(ctx context.Context, request *model.GetAddressBookMembersRequest) (*[]model.AddressBookMemberResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	if _, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, request.AddressBookID, request.UserID, entity.AddressBookRoleViewer); fiberErr != nil {
+		c.Log.Warnf("User %s may not see members of address book %s", request.UserID, request.AddressBookID)
+		return nil, fiberErr
+	}
+
+	var members []entity.AddressBookMember
+	if err := c.AddressBookRepository.FindMembers(ctx, tx, &members, request.AddressBookID); err != nil {
+		c.Log.Warnf("Failed find address book members : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AddressBookMembersToResponses(&members), nil
+}
+
+// UpdateMember lets the owner change another member between editor and viewer
+func (c *AddressBookUseCase) UpdateMember(ctx context.Context, request *model.UpdateAddressBookMemberRequest) (*model.AddressBookMemberResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	if _, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, request.AddressBookID, request.UserID, entity.AddressBookRoleOwner); fiberErr != nil {
+		c.Log.Warnf("User %s may not manage members of address book %s", request.UserID, request.AddressBookID)
+		return nil, fiberErr
+	}
+
+	member := new(entity.AddressBookMember)
+	if err := c.AddressBookRepository.FindMember(ctx, tx, member, request.AddressBookID, request.MemberID); err != nil {
+		c.Log.Warnf("Failed find address book member : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if member.Role == entity.AddressBookRoleOwner {
+		c.Log.Warnf("Owner role of address book %s cannot be changed", request.AddressBookID)
+		return nil, fiber.ErrForbidden
+	}
+
+	member.Role = entity.AddressBookRole(request.Role)
+	if err := c.AddressBookRepository.UpdateMember(tx, member); err != nil {
+		c.Log.Warnf("Failed save address book member : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AddressBookMemberToResponse(member), nil
+}
+
+// DeleteMember removes a member. The owner can remove anyone but themselves,
+// other members can only leave.
+func (c *AddressBookUseCase) DeleteMember(ctx context.Context, request *model.DeleteAddressBookMemberRequest) (*model.AddressBookMemberResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	caller, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, request.AddressBookID, request.UserID, entity.AddressBookRoleViewer)
+	if fiberErr != nil {
+		c.Log.Warnf("User %s is not a member of address book %s", request.UserID, request.AddressBookID)
+		return nil, fiberErr
+	}
+
+	leaving := request.MemberID == request.UserID
+	if !leaving && caller.Role != entity.AddressBookRoleOwner {
+		c.Log.Warnf("User %s may not remove members of address book %s", request.UserID, request.AddressBookID)
+		return nil, fiber.ErrForbidden
+	}
+
+	member := new(entity.AddressBookMember)
+	if err := c.AddressBookRepository.FindMember(ctx, tx, member, request.AddressBookID, request.MemberID); err != nil {
+		c.Log.Warnf("Failed find address book member : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if member.Role == entity.AddressBookRoleOwner {
+		c.Log.Warnf("Owner cannot leave address book %s", request.AddressBookID)
+		return nil, fiber.ErrForbidden
+	}
+
+	if err := c.AddressBookRepository.DeleteMember(tx, member); err != nil {
+		c.Log.Warnf("Failed delete address book member : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AddressBookMemberToResponse(member), nil
+}
+
+// Invite replaces any pending invitation for the address and mails a new one
+func (c *AddressBookUseCase) Invite(ctx context.Context, request *model.CreateAddressBookInvitationRequest) (*model.AddressBookInvitationResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	if _, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, request.AddressBookID, request.UserID, entity.AddressBookRoleOwner); fiberErr != nil {
+		c.Log.Warnf("User %s may not invite to address book %s", request.UserID, request.AddressBookID)
+		return nil, fiberErr
+	}
+
+	book := new(entity.AddressBook)
+	if err := c.AddressBookRepository.FindById(tx, book, request.AddressBookID); err != nil {
+		c.Log.Warnf("Failed find address book by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	inviter := new(entity.User)
+	if err := c.UserRepository.FindById(tx, inviter, request.UserID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	address := strings.ToLower(strings.TrimSpace(request.Email))
+
+	invitee := new(entity.User)
+	if err := c.UserRepository.FindByEmail(tx, invitee, address); err == nil {
+		if err := c.AddressBookRepository.FindMember(ctx, tx, new(entity.AddressBookMember), book.ID, invitee.ID); err == nil {
+			c.Log.Warnf("User %s is already a member of address book %s", invitee.ID, book.ID)
+			return nil, fiber.ErrConflict
+		}
+	}
+
+	if err := c.InvitationRepository.DeleteByBookIDAndEmail(ctx, tx, book.ID, address); err != nil {
+		c.Log.Warnf("Failed delete previous invitations : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	token, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		c.Log.Errorf("Failed to generate invitation token : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	invitation := &entity.AddressBookInvitation{
+		ID:            uuid.New().String(),
+		AddressBookID: book.ID,
+		Email:         address,
+		Role:          entity.AddressBookRole(request.Role),
+		TokenHash:     utils.HashToken(token),
+		InvitedBy:     inviter.ID,
+		ExpiresAt:     time.Now().Add(addressBookInvitationTTL).Unix(),
+		AddressBook:   book,
+	}
+	if err := c.InvitationRepository.Create(tx, invitation); err != nil {
+		c.Log.Warnf("Failed create invitation to database : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// Sent after the commit, so no email carries a token that was never saved.
+	// The owner can invite again to resend it.
+	inviterName := inviter.Name
+	if inviterName == "" {
+		inviterName = inviter.Email
+	}
+	if err := c.EmailClient.SendAddressBookInvitation(address, inviterName, book.Name, request.Role, token, addressBookInvitationTTL); err != nil {
+		c.Log.Warnf("Failed to send invitation email : %+v", err)
+	}
+
+	return converter.AddressBookInvitationToResponse(invitation), nil
+}
+
+// GetInvitations lists the pending invitations sent to the caller's email
+func (c *AddressBookUseCase) GetInvitations(ctx context.Context, request *model.GetAddressBookInvitationRequest) (*[]model.AddressBookInvitationResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	var invitations []entity.AddressBookInvitation
+	if err := c.InvitationRepository.FindPendingByEmail(ctx, tx, &invitations, strings.ToLower(user.Email), time.Now().Unix()); err != nil {
+		c.Log.Warnf("Failed find invitations : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AddressBookInvitationsToResponses(&invitations), nil
+}
+
+func (c *AddressBookUseCase) Accept(ctx context.Context, request *model.RespondAddressBookInvitationRequest) (*model.AddressBookResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	invitation, user, fiberErr := c.findInvitation(ctx, tx, request)
+	if fiberErr != nil {
+		return nil, fiberErr
+	}
+
+	err := c.AddressBookRepository.FindMember(ctx, tx, new(entity.AddressBookMember), invitation.AddressBookID, user.ID)
+	if err == nil {
+		c.Log.Warnf("User %s is already a member of address book %s", user.ID, invitation.AddressBookID)
+		return nil, fiber.ErrConflict
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Log.Warnf("Failed find address book member : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	member := &entity.AddressBookMember{
+		ID:            uuid.New().String(),
+		AddressBookID: invitation.AddressBookID,
+		UserID:        user.ID,
+		Role:          invitation.Role,
+	}
+	if err := c.AddressBookRepository.CreateMember(tx, member); err != nil {
+		c.Log.Warnf("Failed create address book member : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.InvitationRepository.Delete(tx, invitation); err != nil {
+		c.Log.Warnf("Failed delete invitation : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AddressBookToResponse(invitation.AddressBook, member.Role), nil
+}
+
+func (c *AddressBookUseCase) Decline(ctx context.Context, request *model.RespondAddressBookInvitationRequest) (*model.AddressBookInvitationResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	invitation, _, fiberErr := c.findInvitation(ctx, tx, request)
+	if fiberErr != nil {
+		return nil, fiberErr
+	}
+
+	if err := c.InvitationRepository.Delete(tx, invitation); err != nil {
+		c.Log.Warnf("Failed delete invitation : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AddressBookInvitationToResponse(invitation), nil
+}
+
+// findInvitation loads the invitation for token, which only the account with
+// the invited email address may answer.
+func (c *AddressBookUseCase) findInvitation(ctx context.Context, tx *gorm.DB,
+	request *model.RespondAddressBookInvitationRequest) (*entity.AddressBookInvitation, *entity.User, *fiber.Error) {
+	invitation := new(entity.AddressBookInvitation)
+	if err := c.InvitationRepository.FindByHash(ctx, tx, invitation, utils.HashToken(request.Token)); err != nil {
+		c.Log.Warnf("Failed find invitation by token : %+v", err)
+		return nil, nil, fiber.ErrNotFound
+	}
+
+	if invitation.ExpiresAt < time.Now().Unix() {
+		c.Log.Warnf("Invitation %s expired", invitation.ID)
+		return nil, nil, fiber.ErrNotFound
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, nil, fiber.ErrNotFound
+	}
+
+	if user.VerifiedAt == 0 || strings.ToLower(user.Email) != invitation.Email {
+		c.Log.Warnf("Invitation %s was not sent to user %s", invitation.ID, user.ID)
+		return nil, nil, fiber.ErrNotFound
+	}
+
+	return invitation, user, nil
+}
@@ -21,16 +21,18 @@ type ImportantDateUseCase struct {
 	Log                     *logrus.Logger
 	Validate                *validator.Validate
 	ImportantDateRepository *repository.ImportantDateRepository
+	AddressBookRepository   *repository.AddressBookRepository
 	JWTService              *auth.JwtService
 }
 
 func NewImportantDateUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
-	importantDateRepository *repository.ImportantDateRepository, JWTService *auth.JwtService) *ImportantDateUseCase {
+	importantDateRepository *repository.ImportantDateRepository, addressBookRepository *repository.AddressBookRepository, JWTService *auth.JwtService) *ImportantDateUseCase {
 	return &ImportantDateUseCase{
 		DB:                      db,
 		Log:                     logger,
 		Validate:                validate,
 		ImportantDateRepository: importantDateRepository,
+		AddressBookRepository:   addressBookRepository,
 		JWTService:              JWTService,
 	}
 }
@@ -45,9 +47,14 @@ func (c *ImportantDateUseCase) Create(ctx context.Context, request *model.Create
 		return nil, fiber.ErrBadRequest
 	}
 
+	if _, fiberErr := authorizePerson(ctx, tx, c.AddressBookRepository, request.PersonID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit important dates of person %s", request.UserID, request.PersonID)
+		return nil, fiberErr
+	}
+
 	importantDate := new(entity.ImportantDate)
 
-	exists, err := c.ImportantDateRepository.ExistsByName(tx, importantDate, request.Name)
+	exists, err := c.ImportantDateRepository.ExistsByName(tx, request.Name, request.PersonID, "")
 	if err != nil {
 		c.Log.WithError(err).Warn("Failed to check important date existence by name")
 		return nil, fiber.ErrInternalServerError
@@ -61,15 +68,16 @@ func (c *ImportantDateUseCase) Create(ctx context.Context, request *model.Create
 	*importantDate = entity.ImportantDate{
 		ID:        uuid.New().String(),
 		Name:      request.Name,
+		Date:      request.Date,
 		PersonID:  request.PersonID,
 		CreatedAt: time.Time{},
 		UpdatedAt: time.Time{},
 		Person:    nil,
 	}
 
-	if err := c.ImportantDateRepository.Create(tx, importantDate, request.PersonID); err != nil {
+	if err := c.ImportantDateRepository.Create(tx, importantDate); err != nil {
 		c.Log.Warnf("Failed create important date to database : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -80,32 +88,32 @@ func (c *ImportantDateUseCase) Create(ctx context.Context, request *model.Create
 	return converter.ImportantDateToResponse(importantDate), nil
 }
 
-func (c *ImportantDateUseCase) Get(ctx context.Context, request *model.GetImportantDateRequest) (*[]model.ImportantDateResponse, int64, *fiber.Error) {
+func (c *ImportantDateUseCase) Get(ctx context.Context, request *model.GetImportantDateRequest) (*[]model.ImportantDateResponse, *model.PageInfo, *fiber.Error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, 0, fiber.ErrBadRequest
+		return nil, nil, fiber.ErrBadRequest
 	}
 
 	var importantDates []entity.ImportantDate
-	total, err := c.ImportantDateRepository.FindAll(tx, &importantDates, &request.Query)
+	page, err := c.ImportantDateRepository.FindAll(tx, &importantDates, &request.Query, request.UserID)
 	if err != nil {
 		c.Log.Warnf("Failed find important dates : %+v", err)
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, repositoryError(err)
 	}
 
 	if len(importantDates) == 0 {
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, fiber.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, 0, fiber.ErrInternalServerError
+		return nil, nil, fiber.ErrInternalServerError
 	}
 
-	return converter.ImportantDatesToResponses(&importantDates), total, nil
+	return converter.ImportantDatesToResponses(&importantDates), page, nil
 }
 
 func (c *ImportantDateUseCase) Update(ctx context.Context, request *model.UpdateImportantDateRequest) (*model.ImportantDateResponse, *fiber.Error) {
@@ -118,18 +126,34 @@ func (c *ImportantDateUseCase) Update(ctx context.Context, request *model.Update
 	}
 
 	importantDate := new(entity.ImportantDate)
-	if err := c.ImportantDateRepository.FindById(tx, importantDate, request.ID); err != nil {
+	if err := c.ImportantDateRepository.FindById(tx, importantDate, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find important date by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
 
-	if request.Name != "" {
+	if _, fiberErr := authorizePerson(ctx, tx, c.AddressBookRepository, importantDate.PersonID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit important dates of person %s", request.UserID, importantDate.PersonID)
+		return nil, fiberErr
+	}
+
+	if request.Name != "" && request.Name != importantDate.Name {
+		exists, err := c.ImportantDateRepository.ExistsByName(tx, request.Name, importantDate.PersonID, importantDate.ID)
+		if err != nil {
+			c.Log.WithError(err).Warn("Failed to check important date existence by name")
+			return nil, fiber.ErrInternalServerError
+		}
+
+		if exists {
+			c.Log.Warnf("Important date already exists with name: %s", request.Name)
+			return nil, fiber.ErrConflict
+		}
+
 		importantDate.Name = request.Name
 	}
 
-	if err := c.ImportantDateRepository.Update(tx, importantDate); err != nil {
+	if err := c.ImportantDateRepository.Update(tx, importantDate, request.UserID); err != nil {
 		c.Log.Warnf("Failed save important date : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -150,15 +174,21 @@ func (c *ImportantDateUseCase) Delete(ctx context.Context, request *model.Delete
 	}
 
 	importantDate := new(entity.ImportantDate)
-	if err := c.ImportantDateRepository.FindById(tx, importantDate, request.ID); err != nil {
+	if err := c.ImportantDateRepository.FindById(tx, importantDate, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find important date by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
+
+	if _, fiberErr := authorizePerson(ctx, tx, c.AddressBookRepository, importantDate.PersonID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit important dates of person %s", request.UserID, importantDate.PersonID)
+		return nil, fiberErr
+	}
+
 	importantDate.ID = request.ID
 
-	if err := c.ImportantDateRepository.Delete(tx, importantDate); err != nil {
+	if err := c.ImportantDateRepository.Delete(tx, importantDate, request.UserID); err != nil {
 		c.Log.Warnf("Failed save important date : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
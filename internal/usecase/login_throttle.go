@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/repository"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxOtpAttempts       = 5
+	maxChallengeAttempts = 5
+)
+
+// TooManyAttemptsError is returned while a login is locked out. Handlers
+// answer it with 429 and a Retry-After header.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("too many failed attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+type loginThrottlePolicy struct {
+	// failures tolerated before the key gets locked
+	threshold int
+	// failures older than window are forgotten
+	window time.Duration
+	// first lockout, doubled for every further failure up to maxLock
+	baseLock time.Duration
+	maxLock  time.Duration
+}
+
+var (
+	accountThrottle = loginThrottlePolicy{threshold: 5, window: 15 * time.Minute, baseLock: 30 * time.Second, maxLock: time.Hour}
+	ipThrottle      = loginThrottlePolicy{threshold: 20, window: 15 * time.Minute, baseLock: 30 * time.Second, maxLock: time.Hour}
+)
+
+func (p loginThrottlePolicy) lockFor(failures int) time.Duration {
+	if failures < p.threshold {
+		return 0
+	}
+	lock := p.baseLock
+	for i := p.threshold; i < failures && lock < p.maxLock; i++ {
+		lock *= 2
+	}
+	return min(lock, p.maxLock)
+}
+
+type loginThrottleKey struct {
+	id     string
+	policy loginThrottlePolicy
+}
+
+func loginThrottleKeys(email, ip string) []loginThrottleKey {
+	keys := []loginThrottleKey{{id: "account:" + strings.ToLower(email), policy: accountThrottle}}
+	if ip != "" {
+		keys = append(keys, loginThrottleKey{id: "ip:" + ip, policy: ipThrottle})
+	}
+	return keys
+}
+
+// checkLoginThrottle returns a TooManyAttemptsError when any key is locked
+func checkLoginThrottle(ctx context.Context, tx *gorm.DB, repo *repository.LoginAttemptRepository, keys []loginThrottleKey) error {
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.id)
+	}
+
+	var attempts []entity.LoginAttempt
+	if err := repo.FindByIDs(ctx, tx, &attempts, ids); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, attempt := range attempts {
+		if wait := time.Unix(attempt.LockedUntil, 0).Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &TooManyAttemptsError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failure against every key and locks the keys
+// that crossed their threshold
+func recordLoginFailure(ctx context.Context, tx *gorm.DB, repo *repository.LoginAttemptRepository, keys []loginThrottleKey) error {
+	now := time.Now()
+	for _, key := range keys {
+		attempt := &entity.LoginAttempt{ID: key.id}
+		if err := repo.RecordFailure(ctx, tx, attempt, now.Unix(), now.Add(-key.policy.window).Unix()); err != nil {
+			return err
+		}
+
+		if lock := key.policy.lockFor(attempt.Failures); lock > 0 {
+			if err := repo.LockUntil(ctx, tx, key.id, now.Add(lock).Unix()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
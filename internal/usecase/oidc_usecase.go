@@ -0,0 +1,284 @@
+package usecase
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"codename-rl/internal/pkg/oidc"
+	"codename-rl/internal/pkg/utils"
+	"codename-rl/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// oidcStateTTL is how long the user has to finish signing in at the provider
+const oidcStateTTL = 10 * time.Minute
+
+type OidcUseCase struct {
+	DB                 *gorm.DB
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	Providers          *oidc.Registry
+	StateRepository    *repository.OidcStateRepository
+	IdentityRepository *repository.OidcIdentityRepository
+	UserRepository     *repository.UserRepository
+	SessionRepository  *repository.SessionRepository
+	UserUseCase        *UserUseCase
+}
+
+func NewOidcUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, providers *oidc.Registry,
+	stateRepository *repository.OidcStateRepository, identityRepository *repository.OidcIdentityRepository,
+	userRepository *repository.UserRepository, sessionRepository *repository.SessionRepository, userUseCase *UserUseCase) *OidcUseCase {
+	return &OidcUseCase{
+		DB:                 db,
+		Log:                logger,
+		Validate:           validate,
+		Providers:          providers,
+		StateRepository:    stateRepository,
+		IdentityRepository: identityRepository,
+		UserRepository:     userRepository,
+		SessionRepository:  sessionRepository,
+		UserUseCase:        userUseCase,
+	}
+}
+
+// Authorize starts an authorization-code flow and returns the provider URL to
+// send the browser to. The PKCE verifier and nonce stay on the server.
+func (c *OidcUseCase) Authorize(ctx context.Context, request *model.OidcAuthorizeRequest) (*model.OidcAuthorizeResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	provider, ok := c.Providers.Get(request.Provider)
+	if !ok {
+		c.Log.Warnf("Unknown OIDC provider %q", request.Provider)
+		return nil, fiber.ErrNotFound
+	}
+
+	state, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		c.Log.Errorf("Failed to generate OIDC state : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	nonce, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		c.Log.Errorf("Failed to generate OIDC nonce : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	verifier, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		c.Log.Errorf("Failed to generate PKCE verifier : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		c.Log.Warnf("Failed to build OIDC authorization URL : %+v", err)
+		return nil, fiber.ErrBadGateway
+	}
+
+	now := time.Now()
+	if err := c.StateRepository.DeleteExpired(ctx, tx, now.Unix()); err != nil {
+		c.Log.Warnf("Failed delete expired OIDC states : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	pending := &entity.OidcState{
+		ID:           uuid.New().String(),
+		StateHash:    utils.HashToken(state),
+		Provider:     request.Provider,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    now.Add(oidcStateTTL).Unix(),
+	}
+	if err := c.StateRepository.Create(tx, pending); err != nil {
+		c.Log.Warnf("Failed create OIDC state : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.OidcAuthorizeResponse{AuthorizationURL: authorizationURL, State: state}, nil
+}
+
+// Callback redeems the code, then logs in the linked user. An unknown identity
+// is linked to the user with the same verified email, or a new user is created.
+func (c *OidcUseCase) Callback(ctx context.Context, request *model.OidcCallbackRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	provider, ok := c.Providers.Get(request.Provider)
+	if !ok {
+		c.Log.Warnf("Unknown OIDC provider %q", request.Provider)
+		return nil, fiber.ErrNotFound
+	}
+
+	pending := new(entity.OidcState)
+	if err := c.StateRepository.FindByHash(ctx, tx, pending, utils.HashToken(request.State)); err != nil {
+		c.Log.Warnf("Failed find OIDC state : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	// The state is single use whatever the outcome
+	if err := c.StateRepository.Delete(tx, pending); err != nil {
+		c.Log.Warnf("Failed delete OIDC state : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if pending.Provider != request.Provider || pending.ExpiresAt < time.Now().Unix() {
+		c.Log.Warnf("OIDC state expired or issued for another provider")
+		return nil, c.failCallback(tx, fiber.ErrUnauthorized)
+	}
+
+	claims, err := provider.Exchange(ctx, request.Code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		c.Log.Warnf("Failed OIDC code exchange : %+v", err)
+		return nil, c.failCallback(tx, fiber.ErrUnauthorized)
+	}
+
+	// A failed resolve drops its own writes but still consumes the state
+	if err := tx.SavePoint("resolve_user").Error; err != nil {
+		c.Log.Warnf("Failed create savepoint : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	user, err := c.resolveUser(ctx, tx, request.Provider, claims)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			c.Log.Warnf("Failed resolve OIDC user : %+v", err)
+			fiberErr = fiber.ErrInternalServerError
+		}
+		if err := tx.RollbackTo("resolve_user").Error; err != nil {
+			c.Log.Warnf("Failed roll back to savepoint : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		return nil, c.failCallback(tx, fiberErr)
+	}
+
+	return c.UserUseCase.completeLogin(tx, user)
+}
+
+// resolveUser finds the user behind an identity, linking or creating one on
+// first login.
+func (c *OidcUseCase) resolveUser(ctx context.Context, tx *gorm.DB, providerName string, claims *oidc.Claims) (*entity.User, error) {
+	user := new(entity.User)
+
+	identity := new(entity.OidcIdentity)
+	err := c.IdentityRepository.FindByProviderAndSubject(ctx, tx, identity, providerName, claims.Subject)
+	if err == nil {
+		if err := c.UserRepository.FindById(tx, user, identity.UserID); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.EmailVerified {
+		c.Log.Warnf("OIDC identity %s/%s has no verified email", providerName, claims.Subject)
+		return nil, fiber.ErrForbidden
+	}
+
+	err = c.UserRepository.FindByEmail(tx, user, email)
+	switch {
+	case err == nil:
+		if err := c.claimUnverifiedAccount(ctx, tx, user); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := c.createUser(tx, user, email, claims.Name); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	*identity = entity.OidcIdentity{
+		ID:       uuid.New().String(),
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    email,
+	}
+	if err := c.IdentityRepository.Create(tx, identity); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// claimUnverifiedAccount is applied before linking to a local account whose
+// owner never proved the email address: whoever registered it may not own the
+// mailbox, so their password and sessions are dropped.
+func (c *OidcUseCase) claimUnverifiedAccount(ctx context.Context, tx *gorm.DB, user *entity.User) error {
+	if user.VerifiedAt != 0 {
+		return nil
+	}
+
+	password, err := unusablePassword()
+	if err != nil {
+		return err
+	}
+	user.Password = password
+	user.VerifiedAt = time.Now().UnixMilli()
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		return err
+	}
+	return c.SessionRepository.RevokeByUserID(ctx, tx, user.ID)
+}
+
+func (c *OidcUseCase) createUser(tx *gorm.DB, user *entity.User, email string, name string) error {
+	password, err := unusablePassword()
+	if err != nil {
+		return err
+	}
+
+	*user = entity.User{
+		ID:         uuid.New().String(),
+		Email:      email,
+		Password:   password,
+		Name:       name,
+		VerifiedAt: time.Now().UnixMilli(),
+	}
+	return c.UserRepository.Create(tx, user)
+}
+
+// failCallback commits the consumed state so it cannot be replayed, then
+// returns err
+func (c *OidcUseCase) failCallback(tx *gorm.DB, err *fiber.Error) error {
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	return err
+}
+
+// unusablePassword hashes a random secret nobody knows, for accounts that only
+// sign in through a provider until they reset their password.
+func unusablePassword() (string, error) {
+	secret, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+	return utils.HashPassword(secret)
+}
@@ -9,13 +9,13 @@ import (
 	"codename-rl/internal/pkg/utils"
 	"codename-rl/internal/repository"
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -29,6 +29,14 @@ type OtpUseCase struct {
 	JWTService     *auth.JwtService
 }
 
+// otpTTLs is how long an OTP of each purpose stays valid
+var otpTTLs = map[entity.OtpPurpose]time.Duration{
+	entity.OtpPurposeEmailVerification: 15 * time.Minute,
+	entity.OtpPurposePasswordReset:     10 * time.Minute,
+	entity.OtpPurposeEmailChange:       15 * time.Minute,
+	entity.OtpPurposeAccountDeletion:   5 * time.Minute,
+}
+
 func NewOtpUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
 	otpRepository *repository.OtpRepository, UserRepository *repository.UserRepository, emailClient *email.Client, JWTService *auth.JwtService) *OtpUseCase {
 	return &OtpUseCase{
@@ -58,40 +66,47 @@ func (c *OtpUseCase) Create(ctx context.Context, request *model.CreateOtpRequest
 		return nil, fiber.ErrNotFound
 	}
 
-	token, err := c.JWTService.GenerateToken(user, 5*time.Minute)
+	otp, code, err := issueOtp(ctx, tx, c.OtpRepository, c.JWTService, user, entity.OtpPurpose(request.Purpose))
 	if err != nil {
-		c.Log.Errorf("Failed to generate token : %+v", err)
+		c.Log.Warnf("Failed to issue %s OTP : %+v", request.Purpose, err)
 		return nil, fiber.ErrInternalServerError
 	}
 
-	otpNumeric, err := utils.GenerateNumericOTP(6)
-	if err != nil {
-		c.Log.Warnf("Failed to generate OTP : %+v", err)
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
-	otpHashed, err := utils.HashPassword(otpNumeric)
-	if err != nil {
-		c.Log.Warnf("Failed to generate bcrypt hash : %+v", err)
+	if err := sendOtp(c.EmailClient, user.Email, code, otp.Purpose); err != nil {
+		c.Log.Warnf("Failed to send %s OTP : %+v", request.Purpose, err)
 		return nil, fiber.ErrInternalServerError
 	}
+	return converter.OtpToResponse(otp), nil
+}
 
-	otp := &entity.Otp{
-		ID:        uuid.New().String(),
-		Otp:       string(otpHashed),
-		Token:     token,
-		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
-		UserID:    user.ID,
+func (c *OtpUseCase) ResendVerification(ctx context.Context, request *model.ResendVerificationRequest) (*model.OtpResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
 	}
 
-	if err := c.OtpRepository.Create(tx, otp); err != nil {
-		c.Log.Warnf("Failed create otp to database : %+v", err)
-		return nil, fiber.ErrInternalServerError
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserID); err != nil {
+		c.Log.Warnf("Failed to find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
 	}
 
-	// send email here
-	if err := c.EmailClient.SendOTP(request.Email, otpNumeric); err != nil {
-		c.Log.Warnf("Failed to send OTP to email : %+v", err)
+	if user.VerifiedAt != 0 {
+		c.Log.Warnf("User %s already verified", user.ID)
+		return nil, fiber.ErrConflict
+	}
+
+	otp, code, err := issueOtp(ctx, tx, c.OtpRepository, c.JWTService, user, entity.OtpPurposeEmailVerification)
+	if err != nil {
+		c.Log.Warnf("Failed to issue %s OTP : %+v", entity.OtpPurposeEmailVerification, err)
 		return nil, fiber.ErrInternalServerError
 	}
 
@@ -99,10 +114,15 @@ func (c *OtpUseCase) Create(ctx context.Context, request *model.CreateOtpRequest
 		c.Log.Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
+
+	if err := sendOtp(c.EmailClient, user.Email, code, otp.Purpose); err != nil {
+		c.Log.Warnf("Failed to send %s OTP : %+v", entity.OtpPurposeEmailVerification, err)
+		return nil, fiber.ErrInternalServerError
+	}
 	return converter.OtpToResponse(otp), nil
 }
 
-func (c *OtpUseCase) VerifyUser(ctx context.Context, request *model.VerifyOtpRequest) (*model.OtpResponse, error) {
+func (c *OtpUseCase) VerifyUser(ctx context.Context, request *model.VerifyEmailRequest) (*model.OtpResponse, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
@@ -113,8 +133,8 @@ func (c *OtpUseCase) VerifyUser(ctx context.Context, request *model.VerifyOtpReq
 	}
 
 	otp := new(entity.Otp)
-	if err = c.OtpRepository.FindByToken(ctx, tx, otp, request.Token); err != nil {
-		c.Log.Warnf("Failed to find OTP by token : %+v", err)
+	if err = c.OtpRepository.FindByUserID(ctx, tx, otp, request.UserID, entity.OtpPurposeEmailVerification); err != nil {
+		c.Log.Warnf("Failed to find OTP by user id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
 
@@ -128,9 +148,19 @@ func (c *OtpUseCase) VerifyUser(ctx context.Context, request *model.VerifyOtpReq
 		return nil, fiber.ErrBadRequest
 	}
 
+	allowed, err := c.OtpRepository.ConsumeAttempt(ctx, tx, otp.ID, maxOtpAttempts)
+	if err != nil {
+		c.Log.Errorf("Failed to count OTP attempt: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if !allowed {
+		c.Log.Warnf("OTP %s has no attempts left", otp.ID)
+		return nil, fiber.ErrTooManyRequests
+	}
+
 	if err = utils.ComparePassword(otp.Otp, request.Otp); err != nil {
 		c.Log.Warnf("Failed to compare OTP hash : %+v", err)
-		return nil, fiber.ErrUnauthorized
+		return nil, c.failVerification(tx)
 	}
 
 	if err = c.UserRepository.VerifyUser(ctx, tx, otp.UserID); err != nil {
@@ -168,7 +198,7 @@ func (c *OtpUseCase) VerifyForgotPassword(ctx context.Context, request *model.Ve
 		return nil, fiber.ErrBadRequest
 	}
 	otp := new(entity.Otp)
-	if err = c.OtpRepository.FindByToken(ctx, tx, otp, request.Token); err != nil {
+	if err = c.OtpRepository.FindByToken(ctx, tx, otp, request.Token, entity.OtpPurposePasswordReset); err != nil {
 		c.Log.Warnf("Failed to find OTP by token : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
@@ -183,9 +213,19 @@ func (c *OtpUseCase) VerifyForgotPassword(ctx context.Context, request *model.Ve
 		return nil, fiber.ErrBadRequest
 	}
 
-	if err = bcrypt.CompareHashAndPassword([]byte(otp.Otp), []byte(request.Otp)); err != nil {
+	allowed, err := c.OtpRepository.ConsumeAttempt(ctx, tx, otp.ID, maxOtpAttempts)
+	if err != nil {
+		c.Log.Errorf("Failed to count OTP attempt: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if !allowed {
+		c.Log.Warnf("OTP %s has no attempts left", otp.ID)
+		return nil, fiber.ErrTooManyRequests
+	}
+
+	if err = utils.ComparePassword(otp.Otp, request.Otp); err != nil {
 		c.Log.Warnf("Failed to compare OTP hash : %+v", err)
-		return nil, fiber.ErrUnauthorized
+		return nil, c.failVerification(tx)
 	}
 
 	otp.VerifiedAt = time.Now().Unix()
@@ -202,3 +242,63 @@ func (c *OtpUseCase) VerifyForgotPassword(ctx context.Context, request *model.Ve
 	return converter.OtpToResponse(otp), nil
 
 }
+
+// failVerification commits the consumed attempt, which the deferred rollback
+// would otherwise discard, and returns the error for the caller.
+func (c *OtpUseCase) failVerification(tx *gorm.DB) error {
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	return fiber.ErrUnauthorized
+}
+
+// issueOtp replaces any pending OTP of the same purpose for user with a new
+// one in tx and returns its code. The caller mails the code with sendOtp once
+// tx has committed, so no code goes out for an OTP that was never saved.
+func issueOtp(ctx context.Context, tx *gorm.DB, otpRepository *repository.OtpRepository, jwtService *auth.JwtService,
+	user *entity.User, purpose entity.OtpPurpose) (*entity.Otp, string, error) {
+	ttl, ok := otpTTLs[purpose]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown OTP purpose %q", purpose)
+	}
+
+	if err := otpRepository.DeleteByUserIDAndPurpose(ctx, tx, user.ID, purpose); err != nil {
+		return nil, "", err
+	}
+
+	token, err := jwtService.GenerateToken(user, ttl)
+	if err != nil {
+		return nil, "", err
+	}
+
+	otpNumeric, err := utils.GenerateNumericOTP(6)
+	if err != nil {
+		return nil, "", err
+	}
+
+	otpHashed, err := utils.HashPassword(otpNumeric)
+	if err != nil {
+		return nil, "", err
+	}
+
+	otp := &entity.Otp{
+		ID:        uuid.New().String(),
+		Otp:       otpHashed,
+		Token:     token,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+		UserID:    user.ID,
+	}
+
+	if err := otpRepository.Create(tx, otp); err != nil {
+		return nil, "", err
+	}
+
+	return otp, otpNumeric, nil
+}
+
+// sendOtp mails an issued code to the given address
+func sendOtp(emailClient *email.Client, to string, code string, purpose entity.OtpPurpose) error {
+	return emailClient.SendOTP(to, code, string(purpose), otpTTLs[purpose])
+}
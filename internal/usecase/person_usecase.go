@@ -7,6 +7,7 @@ import (
 	"codename-rl/internal/pkg/auth"
 	"codename-rl/internal/repository"
 	"context"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -17,11 +18,12 @@ import (
 )
 
 type PersonUseCase struct {
-	DB               *gorm.DB
-	Log              *logrus.Logger
-	Validate         *validator.Validate
-	PersonRepository *repository.PersonRepository
-	JWTService       *auth.JwtService
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	PersonRepository      *repository.PersonRepository
+	AddressBookRepository *repository.AddressBookRepository
+	JWTService            *auth.JwtService
 }
 
 func NewPersonUseCase(
@@ -29,14 +31,16 @@ func NewPersonUseCase(
 	logger *logrus.Logger,
 	validate *validator.Validate,
 	personRepository *repository.PersonRepository,
+	addressBookRepository *repository.AddressBookRepository,
 	JWTService *auth.JwtService,
 ) *PersonUseCase {
 	return &PersonUseCase{
-		DB:               db,
-		Log:              logger,
-		Validate:         validate,
-		PersonRepository: personRepository,
-		JWTService:       JWTService,
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		PersonRepository:      personRepository,
+		AddressBookRepository: addressBookRepository,
+		JWTService:            JWTService,
 	}
 }
 
@@ -50,23 +54,30 @@ func (c *PersonUseCase) Create(ctx context.Context, request *model.CreatePersonR
 		return nil, fiber.ErrBadRequest
 	}
 
+	member, fiberErr := resolveAddressBook(ctx, tx, c.AddressBookRepository, request.AddressBookID, request.UserID, entity.AddressBookRoleEditor)
+	if fiberErr != nil {
+		c.Log.Warnf("User %s may not add persons to address book %s", request.UserID, request.AddressBookID)
+		return nil, fiberErr
+	}
+
 	person := new(entity.Person)
 
 	*person = entity.Person{
-		ID:          uuid.New().String(),
-		FirstName:   request.FirstName,
-		LastName:    request.LastName,
-		Nickname:    request.Nickname,
-		Avatar:      request.Avatar,
-		Description: request.Description,
-		UserID:      request.UserID,
-		CreatedAt:   time.Time{},
-		UpdatedAt:   time.Time{},
+		ID:            uuid.New().String(),
+		FirstName:     request.FirstName,
+		LastName:      request.LastName,
+		Nickname:      request.Nickname,
+		Avatar:        request.Avatar,
+		Description:   request.Description,
+		UserID:        request.UserID,
+		AddressBookID: member.AddressBookID,
+		CreatedAt:     time.Time{},
+		UpdatedAt:     time.Time{},
 	}
 
 	if err := c.PersonRepository.Create(tx, person, request.TagIDs); err != nil {
 		c.Log.Warnf("Failed create person to database : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -77,32 +88,170 @@ func (c *PersonUseCase) Create(ctx context.Context, request *model.CreatePersonR
 	return converter.PersonToResponse(person), nil
 }
 
-func (c *PersonUseCase) Get(ctx context.Context, request *model.GetPersonRequest) (*[]model.PersonResponse, int64, *fiber.Error) {
+// personRelationPreloads is the Preload path of each person relation a
+// request may omit, keyed by its name in relation filters
+var personRelationPreloads = map[string]string{
+	"phones":          "Phones",
+	"important_dates": "ImportantDates",
+}
+
+// omitRelations takes the omitted relations out of q's includes. A filter on
+// one is refused, as the persons it matches would tell what the rows hold.
+func omitRelations(q *model.Query, omit []string) *fiber.Error {
+	for _, relation := range omit {
+		for _, rf := range q.Relations {
+			if rf.Relation == relation {
+				return fiber.ErrForbidden
+			}
+		}
+
+		preload := personRelationPreloads[relation]
+		under := func(path string) bool {
+			return path == preload || strings.HasPrefix(path, preload+".")
+		}
+		kept := make([]string, 0, len(q.Preload))
+		for _, path := range q.Preload {
+			if !under(path) {
+				kept = append(kept, path)
+			}
+		}
+		q.Preload = kept
+		for path := range q.IncludeFields {
+			if under(path) {
+				delete(q.IncludeFields, path)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *PersonUseCase) Get(ctx context.Context, request *model.GetPersonRequest) (*[]model.PersonResponse, *model.PageInfo, *fiber.Error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, 0, fiber.ErrBadRequest
+		return nil, nil, fiber.ErrBadRequest
+	}
+
+	if fiberErr := omitRelations(&request.Query, request.Omit); fiberErr != nil {
+		c.Log.Warnf("User %s may not filter persons by %v", request.UserID, request.Omit)
+		return nil, nil, fiberErr
 	}
 
 	var persons []entity.Person
-	total, err := c.PersonRepository.FindAll(tx, &persons, &request.Query)
+	page, err := c.PersonRepository.FindAll(tx, &persons, &request.Query, request.UserID)
 	if err != nil {
 		c.Log.Warnf("Failed find persons : %+v", err)
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, repositoryError(err)
 	}
 
 	if len(persons) == 0 {
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, fiber.ErrNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, nil, fiber.ErrInternalServerError
+	}
+
+	return converter.PersonsToResponses(&persons), page, nil
+}
+
+// GetByID returns one person with everything attached to it
+func (c *PersonUseCase) GetByID(ctx context.Context, request *model.GetPersonByIDRequest) (*model.PersonResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	person := new(entity.Person)
+	if err := c.PersonRepository.FindDetail(tx, person, request.ID, request.UserID, request.Omit); err != nil {
+		c.Log.Warnf("Failed find person by id : %+v", err)
+		return nil, repositoryError(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.PersonToResponse(person), nil
+}
+
+// defaultUpcomingDays is how far ahead an important date counts as upcoming
+const defaultUpcomingDays = 30
+
+// GetFacets counts the persons request.Query matches, grouped for the
+// contact sidebar, with the same conditions Get applies.
+func (c *PersonUseCase) GetFacets(ctx context.Context, request *model.GetPersonFacetsRequest) (*model.PersonFacetsResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	if fiberErr := omitRelations(&request.Query, request.Omit); fiberErr != nil {
+		c.Log.Warnf("User %s may not filter persons by %v", request.UserID, request.Omit)
+		return nil, fiberErr
+	}
+
+	days := request.UpcomingDays
+	if days == 0 {
+		days = defaultUpcomingDays
+	}
+
+	facets := new(entity.PersonFacets)
+	if err := c.PersonRepository.Facets(tx, facets, &request.Query, request.UserID, time.Now(), days, request.Omit); err != nil {
+		c.Log.Warnf("Failed count person facets : %+v", err)
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, 0, fiber.ErrInternalServerError
+		return nil, fiber.ErrInternalServerError
 	}
 
-	return converter.PersonsToResponses(&persons), total, nil
+	return converter.PersonFacetsToResponse(facets, days), nil
+}
+
+// defaultSimilarLimit is how many similar persons are returned unless asked otherwise
+const defaultSimilarLimit = 10
+
+// GetSimilar finds persons whose names are close to request.Name, typos
+// included, best match first.
+func (c *PersonUseCase) GetSimilar(ctx context.Context, request *model.GetSimilarPersonRequest) (*[]model.SimilarPersonResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultSimilarLimit
+	}
+
+	var persons []entity.Person
+	similarities, err := c.PersonRepository.FindSimilar(tx, &persons, request.Name, request.UserID, limit)
+	if err != nil {
+		c.Log.Warnf("Failed find similar persons : %+v", err)
+		return nil, repositoryError(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.PersonsToSimilarResponses(&persons, similarities), nil
 }
 
 func (c *PersonUseCase) Update(ctx context.Context, request *model.UpdatePersonRequest) (*model.PersonResponse, *fiber.Error) {
@@ -115,11 +264,16 @@ func (c *PersonUseCase) Update(ctx context.Context, request *model.UpdatePersonR
 	}
 
 	person := new(entity.Person)
-	if err := c.PersonRepository.FindById(tx, person, request.ID); err != nil {
+	if err := c.PersonRepository.FindById(tx, person, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find person by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
 
+	if _, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, person.AddressBookID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit persons in address book %s", request.UserID, person.AddressBookID)
+		return nil, fiberErr
+	}
+
 	if request.FirstName != "" {
 		person.FirstName = request.FirstName
 	}
@@ -136,9 +290,9 @@ func (c *PersonUseCase) Update(ctx context.Context, request *model.UpdatePersonR
 		person.Description = request.Description
 	}
 
-	if err := c.PersonRepository.Update(tx, person, request.TagIDs); err != nil {
+	if err := c.PersonRepository.Update(tx, person, request.TagIDs, request.UserID); err != nil {
 		c.Log.Warnf("Failed save person : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -159,15 +313,21 @@ func (c *PersonUseCase) Delete(ctx context.Context, request *model.DeletePersonR
 	}
 
 	person := new(entity.Person)
-	if err := c.PersonRepository.FindById(tx, person, request.ID); err != nil {
+	if err := c.PersonRepository.FindById(tx, person, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find person by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
+
+	if _, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, person.AddressBookID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit persons in address book %s", request.UserID, person.AddressBookID)
+		return nil, fiberErr
+	}
+
 	person.ID = request.ID
 
-	if err := c.PersonRepository.Delete(tx, person); err != nil {
+	if err := c.PersonRepository.Delete(tx, person, request.UserID); err != nil {
 		c.Log.Warnf("Failed save person : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
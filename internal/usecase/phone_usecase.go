@@ -17,21 +17,23 @@ import (
 )
 
 type PhoneUseCase struct {
-	DB              *gorm.DB
-	Log             *logrus.Logger
-	Validate        *validator.Validate
-	PhoneRepository *repository.PhoneRepository
-	JWTService      *auth.JwtService
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	PhoneRepository       *repository.PhoneRepository
+	AddressBookRepository *repository.AddressBookRepository
+	JWTService            *auth.JwtService
 }
 
 func NewPhoneUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
-	phoneRepository *repository.PhoneRepository, JWTService *auth.JwtService) *PhoneUseCase {
+	phoneRepository *repository.PhoneRepository, addressBookRepository *repository.AddressBookRepository, JWTService *auth.JwtService) *PhoneUseCase {
 	return &PhoneUseCase{
-		DB:              db,
-		Log:             logger,
-		Validate:        validate,
-		PhoneRepository: phoneRepository,
-		JWTService:      JWTService,
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		PhoneRepository:       phoneRepository,
+		AddressBookRepository: addressBookRepository,
+		JWTService:            JWTService,
 	}
 }
 
@@ -45,9 +47,14 @@ func (c *PhoneUseCase) Create(ctx context.Context, request *model.CreatePhoneReq
 		return nil, fiber.ErrBadRequest
 	}
 
+	if _, fiberErr := authorizePerson(ctx, tx, c.AddressBookRepository, request.PersonID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit phones of person %s", request.UserID, request.PersonID)
+		return nil, fiberErr
+	}
+
 	phone := new(entity.Phone)
 
-	exists, err := c.PhoneRepository.ExistsByNumber(tx, phone, request.Number)
+	exists, err := c.PhoneRepository.ExistsByNumber(tx, request.Number, request.PersonID, "")
 	if err != nil {
 		c.Log.WithError(err).Warn("Failed to check phone existence by number")
 		return nil, fiber.ErrInternalServerError
@@ -67,9 +74,9 @@ func (c *PhoneUseCase) Create(ctx context.Context, request *model.CreatePhoneReq
 		Person:    nil,
 	}
 
-	if err := c.PhoneRepository.Create(tx, phone, request.PersonID); err != nil {
+	if err := c.PhoneRepository.Create(tx, phone); err != nil {
 		c.Log.Warnf("Failed create phone to database : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -80,32 +87,32 @@ func (c *PhoneUseCase) Create(ctx context.Context, request *model.CreatePhoneReq
 	return converter.PhoneToResponse(phone), nil
 }
 
-func (c *PhoneUseCase) Get(ctx context.Context, request *model.GetPhoneRequest) (*[]model.PhoneResponse, int64, *fiber.Error) {
+func (c *PhoneUseCase) Get(ctx context.Context, request *model.GetPhoneRequest) (*[]model.PhoneResponse, *model.PageInfo, *fiber.Error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, 0, fiber.ErrBadRequest
+		return nil, nil, fiber.ErrBadRequest
 	}
 
 	var phones []entity.Phone
-	total, err := c.PhoneRepository.FindAll(tx, &phones, &request.Query)
+	page, err := c.PhoneRepository.FindAll(tx, &phones, &request.Query, request.UserID)
 	if err != nil {
 		c.Log.Warnf("Failed find phones : %+v", err)
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, repositoryError(err)
 	}
 
 	if len(phones) == 0 {
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, fiber.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, 0, fiber.ErrInternalServerError
+		return nil, nil, fiber.ErrInternalServerError
 	}
 
-	return converter.PhonesToResponses(&phones), total, nil
+	return converter.PhonesToResponses(&phones), page, nil
 }
 
 func (c *PhoneUseCase) Update(ctx context.Context, request *model.UpdatePhoneRequest) (*model.PhoneResponse, *fiber.Error) {
@@ -118,18 +125,34 @@ func (c *PhoneUseCase) Update(ctx context.Context, request *model.UpdatePhoneReq
 	}
 
 	phone := new(entity.Phone)
-	if err := c.PhoneRepository.FindById(tx, phone, request.ID); err != nil {
+	if err := c.PhoneRepository.FindById(tx, phone, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find phone by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
 
-	if request.Number != "" {
+	if _, fiberErr := authorizePerson(ctx, tx, c.AddressBookRepository, phone.PersonID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit phones of person %s", request.UserID, phone.PersonID)
+		return nil, fiberErr
+	}
+
+	if request.Number != "" && request.Number != phone.Number {
+		exists, err := c.PhoneRepository.ExistsByNumber(tx, request.Number, phone.PersonID, phone.ID)
+		if err != nil {
+			c.Log.WithError(err).Warn("Failed to check phone existence by number")
+			return nil, fiber.ErrInternalServerError
+		}
+
+		if exists {
+			c.Log.Warnf("Phone already exists with number: %s", request.Number)
+			return nil, fiber.ErrConflict
+		}
+
 		phone.Number = request.Number
 	}
 
-	if err := c.PhoneRepository.Update(tx, phone); err != nil {
+	if err := c.PhoneRepository.Update(tx, phone, request.UserID); err != nil {
 		c.Log.Warnf("Failed save phone : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -150,15 +173,21 @@ func (c *PhoneUseCase) Delete(ctx context.Context, request *model.DeletePhoneReq
 	}
 
 	phone := new(entity.Phone)
-	if err := c.PhoneRepository.FindById(tx, phone, request.ID); err != nil {
+	if err := c.PhoneRepository.FindById(tx, phone, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find phone by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
+
+	if _, fiberErr := authorizePerson(ctx, tx, c.AddressBookRepository, phone.PersonID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit phones of person %s", request.UserID, phone.PersonID)
+		return nil, fiberErr
+	}
+
 	phone.ID = request.ID
 
-	if err := c.PhoneRepository.Delete(tx, phone); err != nil {
+	if err := c.PhoneRepository.Delete(tx, phone, request.UserID); err != nil {
 		c.Log.Warnf("Failed save phone : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -21,6 +21,7 @@ type RelationshipUseCase struct {
 	Log                    *logrus.Logger
 	Validate               *validator.Validate
 	RelationshipRepository *repository.RelationshipRepository
+	AddressBookRepository  *repository.AddressBookRepository
 	JWTService             *auth.JwtService
 }
 
@@ -29,6 +30,7 @@ func NewRelationshipUseCase(
 	logger *logrus.Logger,
 	validate *validator.Validate,
 	relationshipRepository *repository.RelationshipRepository,
+	addressBookRepository *repository.AddressBookRepository,
 	JWTService *auth.JwtService,
 ) *RelationshipUseCase {
 	return &RelationshipUseCase{
@@ -36,6 +38,7 @@ func NewRelationshipUseCase(
 		Log:                    logger,
 		Validate:               validate,
 		RelationshipRepository: relationshipRepository,
+		AddressBookRepository:  addressBookRepository,
 		JWTService:             JWTService,
 	}
 }
@@ -50,9 +53,15 @@ func (c *RelationshipUseCase) Create(ctx context.Context, request *model.CreateR
 		return nil, fiber.ErrBadRequest
 	}
 
+	member, fiberErr := resolveAddressBook(ctx, tx, c.AddressBookRepository, request.AddressBookID, request.UserID, entity.AddressBookRoleEditor)
+	if fiberErr != nil {
+		c.Log.Warnf("User %s may not add relationships to address book %s", request.UserID, request.AddressBookID)
+		return nil, fiberErr
+	}
+
 	relationship := new(entity.Relationship)
 
-	exists, err := c.RelationshipRepository.ExistsByName(tx, relationship, request.Name)
+	exists, err := c.RelationshipRepository.ExistsByName(tx, request.Name, member.AddressBookID, "")
 	if err != nil {
 		c.Log.WithError(err).Warn("Failed to check relationship existence by name")
 		return nil, fiber.ErrInternalServerError
@@ -64,17 +73,18 @@ func (c *RelationshipUseCase) Create(ctx context.Context, request *model.CreateR
 	}
 
 	*relationship = entity.Relationship{
-		ID:        uuid.New().String(),
-		Name:      request.Name,
-		UserID:    request.UserID,
-		Color:     request.Color,
-		CreatedAt: time.Time{},
-		UpdatedAt: time.Time{},
+		ID:            uuid.New().String(),
+		Name:          request.Name,
+		UserID:        request.UserID,
+		AddressBookID: member.AddressBookID,
+		Color:         request.Color,
+		CreatedAt:     time.Time{},
+		UpdatedAt:     time.Time{},
 	}
 
 	if err := c.RelationshipRepository.Create(tx, relationship, request.PersonIDs); err != nil {
 		c.Log.Warnf("Failed create relationship to database : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -85,32 +95,32 @@ func (c *RelationshipUseCase) Create(ctx context.Context, request *model.CreateR
 	return converter.RelationshipToResponse(relationship), nil
 }
 
-func (c *RelationshipUseCase) Get(ctx context.Context, request *model.GetRelationshipRequest) (*[]model.RelationshipResponse, int64, *fiber.Error) {
+func (c *RelationshipUseCase) Get(ctx context.Context, request *model.GetRelationshipRequest) (*[]model.RelationshipResponse, *model.PageInfo, *fiber.Error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, 0, fiber.ErrBadRequest
+		return nil, nil, fiber.ErrBadRequest
 	}
 
 	var relationships []entity.Relationship
-	total, err := c.RelationshipRepository.FindAll(tx, &relationships, &request.Query)
+	page, err := c.RelationshipRepository.FindAll(tx, &relationships, &request.Query, request.UserID)
 	if err != nil {
 		c.Log.Warnf("Failed find relationships : %+v", err)
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, repositoryError(err)
 	}
 
 	if len(relationships) == 0 {
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, fiber.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, 0, fiber.ErrInternalServerError
+		return nil, nil, fiber.ErrInternalServerError
 	}
 
-	return converter.RelationshipsToResponses(&relationships), total, nil
+	return converter.RelationshipsToResponses(&relationships), page, nil
 }
 
 func (c *RelationshipUseCase) Update(ctx context.Context, request *model.UpdateRelationshipRequest) (*model.RelationshipResponse, *fiber.Error) {
@@ -123,18 +133,34 @@ func (c *RelationshipUseCase) Update(ctx context.Context, request *model.UpdateR
 	}
 
 	relationship := new(entity.Relationship)
-	if err := c.RelationshipRepository.FindById(tx, relationship, request.ID); err != nil {
+	if err := c.RelationshipRepository.FindById(tx, relationship, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find relationship by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
 
-	if request.Name != "" {
+	if _, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, relationship.AddressBookID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit relationships in address book %s", request.UserID, relationship.AddressBookID)
+		return nil, fiberErr
+	}
+
+	if request.Name != "" && request.Name != relationship.Name {
+		exists, err := c.RelationshipRepository.ExistsByName(tx, request.Name, relationship.AddressBookID, relationship.ID)
+		if err != nil {
+			c.Log.WithError(err).Warn("Failed to check relationship existence by name")
+			return nil, fiber.ErrInternalServerError
+		}
+
+		if exists {
+			c.Log.Warnf("Relationship already exists with name: %s", request.Name)
+			return nil, fiber.ErrConflict
+		}
+
 		relationship.Name = request.Name
 	}
 
-	if err := c.RelationshipRepository.Update(tx, relationship, request.PersonIDs); err != nil {
+	if err := c.RelationshipRepository.Update(tx, relationship, request.PersonIDs, request.UserID); err != nil {
 		c.Log.Warnf("Failed save relationship : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -155,15 +181,21 @@ func (c *RelationshipUseCase) Delete(ctx context.Context, request *model.DeleteR
 	}
 
 	relationship := new(entity.Relationship)
-	if err := c.RelationshipRepository.FindById(tx, relationship, request.ID); err != nil {
+	if err := c.RelationshipRepository.FindById(tx, relationship, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find relationship by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
+
+	if _, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, relationship.AddressBookID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit relationships in address book %s", request.UserID, relationship.AddressBookID)
+		return nil, fiberErr
+	}
+
 	relationship.ID = request.ID
 
-	if err := c.RelationshipRepository.Delete(tx, relationship); err != nil {
+	if err := c.RelationshipRepository.Delete(tx, relationship, request.UserID); err != nil {
 		c.Log.Warnf("Failed save relationship : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
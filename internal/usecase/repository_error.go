@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"codename-rl/internal/repository"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// repositoryError maps a failed repository call to a response. A missing row,
+// including one owned by another user or a referenced ID the caller does not
+// own, is 404; losing a race on a unique name is 409; a query on a field the
+// entity does not allow, or a malformed filter or sort, is 400 naming that
+// field; a cursor that does not fit the query is 400 too.
+func repositoryError(err error) *fiber.Error {
+	var fieldErr *repository.QueryFieldError
+	if errors.As(err, &fieldErr) {
+		return fiber.NewError(fiber.StatusBadRequest, fieldErr.Error())
+	}
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	var filterErr *repository.FilterError
+	if errors.As(err, &filterErr) {
+		return fiber.NewError(fiber.StatusBadRequest, filterErr.Error())
+	}
+	var sortErr *repository.SortError
+	if errors.As(err, &sortErr) {
+		return fiber.NewError(fiber.StatusBadRequest, sortErr.Error())
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.ErrNotFound
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fiber.ErrConflict
+	}
+	return fiber.ErrInternalServerError
+}
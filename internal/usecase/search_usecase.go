@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"codename-rl/internal/model/converter"
+	"codename-rl/internal/repository"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// defaultSearchLimit is how many hits a search returns unless asked otherwise
+const defaultSearchLimit = 20
+
+type SearchUseCase struct {
+	DB               *gorm.DB
+	Log              *logrus.Logger
+	Validate         *validator.Validate
+	SearchRepository *repository.SearchRepository
+}
+
+func NewSearchUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	searchRepository *repository.SearchRepository) *SearchUseCase {
+	return &SearchUseCase{
+		DB:               db,
+		Log:              logger,
+		Validate:         validate,
+		SearchRepository: searchRepository,
+	}
+}
+
+func (c *SearchUseCase) Search(ctx context.Context, request *model.SearchRequest) (*[]model.SearchHitResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	hits := []entity.SearchHit{}
+	if err := c.SearchRepository.Search(ctx, tx, &hits, request.Query, request.Types, request.UserID, limit); err != nil {
+		c.Log.Warnf("Failed search : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SearchHitsToResponses(&hits), nil
+}
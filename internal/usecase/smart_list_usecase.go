@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"codename-rl/internal/model/converter"
+	"codename-rl/internal/repository"
+	"context"
+	"encoding/json"
+	"maps"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultSmartListLimit is the page size of a smart list saved without one
+	defaultSmartListLimit = 20
+	// maxSmartListLimit bounds the page size a smart list is saved or run with
+	maxSmartListLimit = 100
+)
+
+type SmartListUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validate            *validator.Validate
+	SmartListRepository *repository.SmartListRepository
+	PersonRepository    *repository.PersonRepository
+}
+
+func NewSmartListUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	smartListRepository *repository.SmartListRepository, personRepository *repository.PersonRepository) *SmartListUseCase {
+	return &SmartListUseCase{
+		DB:                  db,
+		Log:                 logger,
+		Validate:            validate,
+		SmartListRepository: smartListRepository,
+		PersonRepository:    personRepository,
+	}
+}
+
+func (c *SmartListUseCase) Create(ctx context.Context, request *model.CreateSmartListRequest) (*model.SmartListResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	exists, err := c.SmartListRepository.ExistsByName(ctx, tx, request.Name, request.UserID, "")
+	if err != nil {
+		c.Log.WithError(err).Warn("Failed to check smart list existence by name")
+		return nil, fiber.ErrInternalServerError
+	}
+	if exists {
+		c.Log.Warnf("Smart list already exists with name: %s", request.Name)
+		return nil, fiber.ErrConflict
+	}
+
+	query, fiberErr := c.encodeQuery(tx, &request.Query, request.UserID, request.Omit)
+	if fiberErr != nil {
+		return nil, fiberErr
+	}
+
+	list := &entity.SmartList{
+		ID:       uuid.New().String(),
+		UserID:   request.UserID,
+		Name:     request.Name,
+		Query:    query,
+		Pinned:   request.Pinned,
+		Position: request.Position,
+	}
+
+	if err := c.SmartListRepository.Create(tx, list); err != nil {
+		c.Log.Warnf("Failed create smart list : %+v", err)
+		return nil, repositoryError(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SmartListToResponse(list), nil
+}
+
+// Get lists the caller's smart lists, pinned ones first in dashboard order
+func (c *SmartListUseCase) Get(ctx context.Context, request *model.GetSmartListRequest) (*[]model.SmartListResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	var lists []entity.SmartList
+	if err := c.SmartListRepository.FindAllByUserID(ctx, tx, &lists, request.UserID, request.Pinned); err != nil {
+		c.Log.Warnf("Failed find smart lists : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SmartListsToResponses(&lists), nil
+}
+
+func (c *SmartListUseCase) GetByID(ctx context.Context, request *model.GetSmartListByIDRequest) (*model.SmartListResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	list := new(entity.SmartList)
+	if err := c.SmartListRepository.FindByIDAndUserID(ctx, tx, list, request.ID, request.UserID); err != nil {
+		c.Log.Warnf("Failed find smart list by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SmartListToResponse(list), nil
+}
+
+func (c *SmartListUseCase) Update(ctx context.Context, request *model.UpdateSmartListRequest) (*model.SmartListResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	list := new(entity.SmartList)
+	if err := c.SmartListRepository.FindByIDAndUserID(ctx, tx, list, request.ID, request.UserID); err != nil {
+		c.Log.Warnf("Failed find smart list by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if request.Name != "" && request.Name != list.Name {
+		exists, err := c.SmartListRepository.ExistsByName(ctx, tx, request.Name, request.UserID, list.ID)
+		if err != nil {
+			c.Log.WithError(err).Warn("Failed to check smart list existence by name")
+			return nil, fiber.ErrInternalServerError
+		}
+		if exists {
+			c.Log.Warnf("Smart list already exists with name: %s", request.Name)
+			return nil, fiber.ErrConflict
+		}
+		list.Name = request.Name
+	}
+
+	if request.Query != nil {
+		query, fiberErr := c.encodeQuery(tx, request.Query, request.UserID, request.Omit)
+		if fiberErr != nil {
+			return nil, fiberErr
+		}
+		list.Query = query
+	}
+	if request.Pinned != nil {
+		list.Pinned = *request.Pinned
+	}
+	if request.Position != nil {
+		list.Position = *request.Position
+	}
+
+	if err := c.SmartListRepository.Update(tx, list); err != nil {
+		c.Log.Warnf("Failed save smart list : %+v", err)
+		return nil, repositoryError(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SmartListToResponse(list), nil
+}
+
+func (c *SmartListUseCase) Delete(ctx context.Context, request *model.DeleteSmartListRequest) (*model.SmartListResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	list := new(entity.SmartList)
+	if err := c.SmartListRepository.FindByIDAndUserID(ctx, tx, list, request.ID, request.UserID); err != nil {
+		c.Log.Warnf("Failed find smart list by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if err := c.SmartListRepository.Delete(tx, list); err != nil {
+		c.Log.Warnf("Failed delete smart list : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SmartListToResponse(list), nil
+}
+
+// Evaluate runs a saved list against the persons the caller can see now. It
+// returns the query it ran, so the response can be trimmed to its fields.
+func (c *SmartListUseCase) Evaluate(ctx context.Context, request *model.EvaluateSmartListRequest) (*[]model.PersonResponse, *model.Query, *model.PageInfo, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, nil, nil, fiber.ErrBadRequest
+	}
+
+	list := new(entity.SmartList)
+	if err := c.SmartListRepository.FindByIDAndUserID(ctx, tx, list, request.ID, request.UserID); err != nil {
+		c.Log.Warnf("Failed find smart list by id : %+v", err)
+		return nil, nil, nil, fiber.ErrNotFound
+	}
+
+	query := new(model.Query)
+	if err := json.Unmarshal([]byte(list.Query), query); err != nil {
+		c.Log.Warnf("Failed decode smart list query : %+v", err)
+		return nil, nil, nil, fiber.ErrInternalServerError
+	}
+	if request.Limit > 0 {
+		query.Limit = request.Limit
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultSmartListLimit
+	}
+	// Lists saved before the bound was checked may hold a larger page size
+	if query.Limit > maxSmartListLimit {
+		query.Limit = maxSmartListLimit
+	}
+	query.Offset = request.Offset
+	query.Cursor = request.Cursor
+	query.SkipTotal = query.SkipTotal || request.SkipTotal
+	if fiberErr := omitRelations(query, request.Omit); fiberErr != nil {
+		c.Log.Warnf("User %s may not filter persons by %v", request.UserID, request.Omit)
+		return nil, nil, nil, fiberErr
+	}
+
+	var persons []entity.Person
+	page, err := c.PersonRepository.FindAll(tx, &persons, query, request.UserID)
+	if err != nil {
+		c.Log.Warnf("Failed evaluate smart list : %+v", err)
+		return nil, nil, nil, repositoryError(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, nil, nil, fiber.ErrInternalServerError
+	}
+
+	return converter.PersonsToResponses(&persons), query, page, nil
+}
+
+// encodeQuery runs q once, so only a query that evaluates is saved, and
+// returns it as JSON without the paging position of the page it came from.
+// Includes of omitted relations are kept, for callers that may read them.
+func (c *SmartListUseCase) encodeQuery(tx *gorm.DB, q *model.Query, userID string, omit []string) (string, *fiber.Error) {
+	if q.Limit < 0 || q.Limit > maxSmartListLimit {
+		c.Log.Warnf("Invalid smart list limit : %d", q.Limit)
+		return "", fiber.ErrBadRequest
+	}
+
+	saved := *q
+	saved.Offset = 0
+	saved.Cursor = ""
+
+	probe := saved
+	probe.Limit = 1
+	probe.SkipTotal = true
+	probe.IncludeFields = maps.Clone(saved.IncludeFields)
+	if fiberErr := omitRelations(&probe, omit); fiberErr != nil {
+		c.Log.Warnf("User %s may not filter persons by %v", userID, omit)
+		return "", fiberErr
+	}
+	var persons []entity.Person
+	if _, err := c.PersonRepository.FindAll(tx, &persons, &probe, userID); err != nil {
+		c.Log.Warnf("Invalid smart list query : %+v", err)
+		return "", repositoryError(err)
+	}
+
+	raw, err := json.Marshal(saved)
+	if err != nil {
+		c.Log.Warnf("Failed encode smart list query : %+v", err)
+		return "", fiber.ErrInternalServerError
+	}
+	return string(raw), nil
+}
@@ -17,21 +17,23 @@ import (
 )
 
 type TagUseCase struct {
-	DB            *gorm.DB
-	Log           *logrus.Logger
-	Validate      *validator.Validate
-	TagRepository *repository.TagRepository
-	JWTService    *auth.JwtService
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	TagRepository         *repository.TagRepository
+	AddressBookRepository *repository.AddressBookRepository
+	JWTService            *auth.JwtService
 }
 
 func NewTagUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
-	tagRepository *repository.TagRepository, JWTService *auth.JwtService) *TagUseCase {
+	tagRepository *repository.TagRepository, addressBookRepository *repository.AddressBookRepository, JWTService *auth.JwtService) *TagUseCase {
 	return &TagUseCase{
-		DB:            db,
-		Log:           logger,
-		Validate:      validate,
-		TagRepository: tagRepository,
-		JWTService:    JWTService,
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		TagRepository:         tagRepository,
+		AddressBookRepository: addressBookRepository,
+		JWTService:            JWTService,
 	}
 }
 
@@ -45,9 +47,15 @@ func (c *TagUseCase) Create(ctx context.Context, request *model.CreateTagRequest
 		return nil, fiber.ErrBadRequest
 	}
 
+	member, fiberErr := resolveAddressBook(ctx, tx, c.AddressBookRepository, request.AddressBookID, request.UserID, entity.AddressBookRoleEditor)
+	if fiberErr != nil {
+		c.Log.Warnf("User %s may not add tags to address book %s", request.UserID, request.AddressBookID)
+		return nil, fiberErr
+	}
+
 	tag := new(entity.Tag)
 
-	exists, err := c.TagRepository.ExistsByName(tx, tag, request.Name)
+	exists, err := c.TagRepository.ExistsByName(tx, request.Name, member.AddressBookID, "")
 	if err != nil {
 		c.Log.WithError(err).Warn("Failed to check tag existence by email")
 		return nil, fiber.ErrInternalServerError
@@ -59,18 +67,19 @@ func (c *TagUseCase) Create(ctx context.Context, request *model.CreateTagRequest
 	}
 
 	*tag = entity.Tag{
-		ID:        uuid.New().String(),
-		Name:      request.Name,
-		UserID:    request.UserID,
-		CreatedAt: time.Time{},
-		UpdatedAt: time.Time{},
-		Persons:   nil,
-		User:      nil,
+		ID:            uuid.New().String(),
+		Name:          request.Name,
+		UserID:        request.UserID,
+		AddressBookID: member.AddressBookID,
+		CreatedAt:     time.Time{},
+		UpdatedAt:     time.Time{},
+		Persons:       nil,
+		User:          nil,
 	}
 
 	if err := c.TagRepository.Create(tx, tag, request.PersonIDs); err != nil {
 		c.Log.Warnf("Failed create tag to database : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -81,32 +90,32 @@ func (c *TagUseCase) Create(ctx context.Context, request *model.CreateTagRequest
 	return converter.TagToResponse(tag), nil
 }
 
-func (c *TagUseCase) Get(ctx context.Context, request *model.GetTagRequest) (*[]model.TagResponse, int64, *fiber.Error) {
+func (c *TagUseCase) Get(ctx context.Context, request *model.GetTagRequest) (*[]model.TagResponse, *model.PageInfo, *fiber.Error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, 0, fiber.ErrBadRequest
+		return nil, nil, fiber.ErrBadRequest
 	}
 
 	var tags []entity.Tag
-	total, err := c.TagRepository.FindAll(tx, &tags, &request.Query)
+	page, err := c.TagRepository.FindAll(tx, &tags, &request.Query, request.UserID)
 	if err != nil {
 		c.Log.Warnf("Failed find tags : %+v", err)
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, repositoryError(err)
 	}
 
 	if len(tags) == 0 {
-		return nil, 0, fiber.ErrNotFound
+		return nil, nil, fiber.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
-		return nil, 0, fiber.ErrInternalServerError
+		return nil, nil, fiber.ErrInternalServerError
 	}
 
-	return converter.TagsToResponses(&tags), total, nil
+	return converter.TagsToResponses(&tags), page, nil
 }
 
 func (c *TagUseCase) Update(ctx context.Context, request *model.UpdateTagRequest) (*model.TagResponse, *fiber.Error) {
@@ -119,18 +128,34 @@ func (c *TagUseCase) Update(ctx context.Context, request *model.UpdateTagRequest
 	}
 
 	tag := new(entity.Tag)
-	if err := c.TagRepository.FindById(tx, tag, request.ID); err != nil {
+	if err := c.TagRepository.FindById(tx, tag, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find tag by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
 
-	if request.Name != "" {
+	if _, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, tag.AddressBookID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit tags in address book %s", request.UserID, tag.AddressBookID)
+		return nil, fiberErr
+	}
+
+	if request.Name != "" && request.Name != tag.Name {
+		exists, err := c.TagRepository.ExistsByName(tx, request.Name, tag.AddressBookID, tag.ID)
+		if err != nil {
+			c.Log.WithError(err).Warn("Failed to check tag existence by name")
+			return nil, fiber.ErrInternalServerError
+		}
+
+		if exists {
+			c.Log.Warnf("Tag already exists with name: %s", request.Name)
+			return nil, fiber.ErrConflict
+		}
+
 		tag.Name = request.Name
 	}
 
-	if err := c.TagRepository.Update(tx, tag); err != nil {
+	if err := c.TagRepository.Update(tx, tag, request.UserID); err != nil {
 		c.Log.Warnf("Failed save tag : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -151,15 +176,21 @@ func (c *TagUseCase) Delete(ctx context.Context, request *model.DeleteTagRequest
 	}
 
 	tag := new(entity.Tag)
-	if err := c.TagRepository.FindById(tx, tag, request.ID); err != nil {
+	if err := c.TagRepository.FindById(tx, tag, request.ID, request.UserID); err != nil {
 		c.Log.Warnf("Failed find tag by id : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
+
+	if _, fiberErr := authorizeAddressBook(ctx, tx, c.AddressBookRepository, tag.AddressBookID, request.UserID, entity.AddressBookRoleEditor); fiberErr != nil {
+		c.Log.Warnf("User %s may not edit tags in address book %s", request.UserID, tag.AddressBookID)
+		return nil, fiberErr
+	}
+
 	tag.ID = request.ID
 
-	if err := c.TagRepository.Delete(tx, tag); err != nil {
+	if err := c.TagRepository.Delete(tx, tag, request.UserID); err != nil {
 		c.Log.Warnf("Failed save tag : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		return nil, repositoryError(err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
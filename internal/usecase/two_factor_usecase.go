@@ -0,0 +1,331 @@
+package usecase
+
+import (
+	"codename-rl/internal/entity"
+	"codename-rl/internal/model"
+	"codename-rl/internal/model/converter"
+	"codename-rl/internal/pkg/utils"
+	"codename-rl/internal/repository"
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer        = "codename-rl"
+	recoveryCodeCount = 10
+)
+
+type TwoFactorUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	UserRepository         *repository.UserRepository
+	RecoveryCodeRepository *repository.RecoveryCodeRepository
+	LoginAttemptRepository *repository.LoginAttemptRepository
+}
+
+func NewTwoFactorUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	userRepository *repository.UserRepository, recoveryCodeRepository *repository.RecoveryCodeRepository,
+	loginAttemptRepository *repository.LoginAttemptRepository) *TwoFactorUseCase {
+	return &TwoFactorUseCase{
+		DB:                     db,
+		Log:                    logger,
+		Validate:               validate,
+		UserRepository:         userRepository,
+		RecoveryCodeRepository: recoveryCodeRepository,
+		LoginAttemptRepository: loginAttemptRepository,
+	}
+}
+
+// Enroll stores a fresh secret that only takes effect once Confirm succeeds
+func (c *TwoFactorUseCase) Enroll(ctx context.Context, request *model.EnrollTwoFactorRequest) (*model.TwoFactorEnrollResponse, *fiber.Error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if user.TotpEnabledAt != 0 {
+		c.Log.Warnf("Two-factor authentication already enabled for user %s", user.ID)
+		return nil, fiber.ErrConflict
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.Log.Warnf("Failed to generate TOTP secret : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	user.TotpSecret = secret
+	user.TotpLastStep = 0
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.TwoFactorEnrollResponse{
+		Secret: secret,
+		URI:    utils.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once a code from the pending
+// secret checks out. Wrong codes count against the login throttle.
+func (c *TwoFactorUseCase) Confirm(ctx context.Context, request *model.ConfirmTwoFactorRequest) (*model.RecoveryCodesResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if user.TotpSecret == "" || user.TotpEnabledAt != 0 {
+		c.Log.Warnf("No pending two-factor enrolment for user %s", user.ID)
+		return nil, fiber.ErrBadRequest
+	}
+
+	keys := loginThrottleKeys(user.Email, request.IP)
+	if err := checkLoginThrottle(ctx, tx, c.LoginAttemptRepository, keys); err != nil {
+		c.Log.Warnf("Two-factor attempts throttled for %s : %+v", user.Email, err)
+		return nil, err
+	}
+
+	step, ok := utils.ValidateTOTP(user.TotpSecret, request.Code, time.Now())
+	if !ok {
+		c.Log.Warnf("Invalid TOTP code for user %s", user.ID)
+		return nil, c.failAttempt(ctx, tx, keys)
+	}
+
+	user.TotpEnabledAt = time.Now().UnixMilli()
+	user.TotpLastStep = step
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	codes, err := generateRecoveryCodes(ctx, tx, c.RecoveryCodeRepository, user.ID)
+	if err != nil {
+		c.Log.Warnf("Failed to generate recovery codes : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (c *TwoFactorUseCase) Disable(ctx context.Context, request *model.DisableTwoFactorRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if user.TotpEnabledAt == 0 {
+		c.Log.Warnf("Two-factor authentication not enabled for user %s", user.ID)
+		return nil, fiber.ErrBadRequest
+	}
+
+	keys := loginThrottleKeys(user.Email, request.IP)
+	if err := checkLoginThrottle(ctx, tx, c.LoginAttemptRepository, keys); err != nil {
+		c.Log.Warnf("Two-factor attempts throttled for %s : %+v", user.Email, err)
+		return nil, err
+	}
+
+	ok, err := verifySecondFactor(ctx, tx, c.RecoveryCodeRepository, user, request.Code)
+	if err != nil {
+		c.Log.Warnf("Failed to verify second factor : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if !ok {
+		c.Log.Warnf("Invalid second factor for user %s", user.ID)
+		return nil, c.failAttempt(ctx, tx, keys)
+	}
+
+	user.TotpSecret = ""
+	user.TotpEnabledAt = 0
+	user.TotpLastStep = 0
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.RecoveryCodeRepository.DeleteByUserID(ctx, tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete recovery codes : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+func (c *TwoFactorUseCase) RegenerateRecoveryCodes(ctx context.Context, request *model.RegenerateRecoveryCodesRequest) (*model.RecoveryCodesResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if user.TotpEnabledAt == 0 {
+		c.Log.Warnf("Two-factor authentication not enabled for user %s", user.ID)
+		return nil, fiber.ErrBadRequest
+	}
+
+	keys := loginThrottleKeys(user.Email, request.IP)
+	if err := checkLoginThrottle(ctx, tx, c.LoginAttemptRepository, keys); err != nil {
+		c.Log.Warnf("Two-factor attempts throttled for %s : %+v", user.Email, err)
+		return nil, err
+	}
+
+	// Only a TOTP code is accepted here, a recovery code would be wiped anyway
+	step, ok := utils.ValidateTOTP(user.TotpSecret, request.Code, time.Now())
+	if !ok || step <= user.TotpLastStep {
+		c.Log.Warnf("Invalid TOTP code for user %s", user.ID)
+		return nil, c.failAttempt(ctx, tx, keys)
+	}
+
+	user.TotpLastStep = step
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	codes, err := generateRecoveryCodes(ctx, tx, c.RecoveryCodeRepository, user.ID)
+	if err != nil {
+		c.Log.Warnf("Failed to generate recovery codes : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// failAttempt counts a wrong code against the same keys as a failed login,
+// so guessing codes here locks the account as guessing them at login does.
+// It commits tx.
+func (c *TwoFactorUseCase) failAttempt(ctx context.Context, tx *gorm.DB, keys []loginThrottleKey) error {
+	if err := recordLoginFailure(ctx, tx, c.LoginAttemptRepository, keys); err != nil {
+		c.Log.Warnf("Failed record two-factor failure : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	return fiber.ErrUnauthorized
+}
+
+// verifySecondFactor accepts a TOTP code for a step newer than the last used
+// one, or an unused recovery code, which is consumed. The caller persists user.
+func verifySecondFactor(ctx context.Context, tx *gorm.DB, recoveryCodeRepository *repository.RecoveryCodeRepository,
+	user *entity.User, code string) (bool, error) {
+	if step, ok := utils.ValidateTOTP(user.TotpSecret, code, time.Now()); ok {
+		if step <= user.TotpLastStep {
+			return false, nil
+		}
+		user.TotpLastStep = step
+		return true, nil
+	}
+
+	var codes []entity.RecoveryCode
+	if err := recoveryCodeRepository.FindUnusedByUserID(ctx, tx, &codes, user.ID); err != nil {
+		return false, err
+	}
+
+	for _, recoveryCode := range codes {
+		if utils.ComparePassword(recoveryCode.CodeHash, code) == nil {
+			return true, recoveryCodeRepository.MarkUsed(ctx, tx, recoveryCode.ID)
+		}
+	}
+
+	return false, nil
+}
+
+// generateRecoveryCodes replaces every recovery code of the user and returns
+// the new plain codes, which are only ever shown once.
+func generateRecoveryCodes(ctx context.Context, tx *gorm.DB, recoveryCodeRepository *repository.RecoveryCodeRepository,
+	userID string) ([]string, error) {
+	if err := recoveryCodeRepository.DeleteByUserID(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		hashed, err := utils.HashPassword(code)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := recoveryCodeRepository.Create(tx, &entity.RecoveryCode{
+			ID:       uuid.New().String(),
+			UserID:   userID,
+			CodeHash: hashed,
+		}); err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
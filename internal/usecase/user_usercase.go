@@ -5,9 +5,11 @@ import (
 	"codename-rl/internal/model"
 	"codename-rl/internal/model/converter"
 	"codename-rl/internal/pkg/auth"
+	"codename-rl/internal/pkg/email"
 	"codename-rl/internal/pkg/utils"
 	"codename-rl/internal/repository"
 	"context"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -17,24 +19,48 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	accessTokenTTL        = 15 * time.Minute
+	refreshTokenTTL       = 30 * 24 * time.Hour
+	twoFactorChallengeTTL = 5 * time.Minute
+)
+
 type UserUseCase struct {
-	DB             *gorm.DB
-	Log            *logrus.Logger
-	Validate       *validator.Validate
-	UserRepository *repository.UserRepository
-	OtpRepository  *repository.OtpRepository
-	JWTService     *auth.JwtService
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	UserRepository         *repository.UserRepository
+	OtpRepository          *repository.OtpRepository
+	SessionRepository      *repository.SessionRepository
+	RefreshTokenRepository *repository.RefreshTokenRepository
+	RecoveryCodeRepository *repository.RecoveryCodeRepository
+	ChallengeRepository    *repository.TwoFactorChallengeRepository
+	LoginAttemptRepository *repository.LoginAttemptRepository
+	AccessTokenRepository  *repository.AccessTokenRepository
+	EmailClient            *email.Client
+	JWTService             *auth.JwtService
 }
 
 func NewUserUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
-	userRepository *repository.UserRepository, OtpRepository *repository.OtpRepository, JWTService *auth.JwtService) *UserUseCase {
+	userRepository *repository.UserRepository, OtpRepository *repository.OtpRepository,
+	sessionRepository *repository.SessionRepository, refreshTokenRepository *repository.RefreshTokenRepository,
+	recoveryCodeRepository *repository.RecoveryCodeRepository, challengeRepository *repository.TwoFactorChallengeRepository,
+	loginAttemptRepository *repository.LoginAttemptRepository, accessTokenRepository *repository.AccessTokenRepository,
+	emailClient *email.Client, JWTService *auth.JwtService) *UserUseCase {
 	return &UserUseCase{
-		DB:             db,
-		Log:            logger,
-		Validate:       validate,
-		UserRepository: userRepository,
-		OtpRepository:  OtpRepository,
-		JWTService:     JWTService,
+		DB:                     db,
+		Log:                    logger,
+		Validate:               validate,
+		UserRepository:         userRepository,
+		OtpRepository:          OtpRepository,
+		SessionRepository:      sessionRepository,
+		RefreshTokenRepository: refreshTokenRepository,
+		RecoveryCodeRepository: recoveryCodeRepository,
+		ChallengeRepository:    challengeRepository,
+		LoginAttemptRepository: loginAttemptRepository,
+		AccessTokenRepository:  accessTokenRepository,
+		EmailClient:            emailClient,
+		JWTService:             JWTService,
 	}
 }
 
@@ -47,17 +73,64 @@ func (c *UserUseCase) Verify(ctx context.Context, request *model.VerifyUserReque
 		c.Log.Warnf("Invalid request body : %+v", err)
 		return nil, fiber.ErrBadRequest
 	}
+
+	if strings.HasPrefix(request.Token, model.AccessTokenPrefix) {
+		return c.verifyAccessToken(ctx, tx, request.Token)
+	}
+
 	claims, err := c.JWTService.ValidateToken(request.Token)
 	if err != nil || claims == nil {
 		c.Log.Warnf("Failed find user by token : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
 
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindActive(ctx, tx, session, claims.RegisteredClaims.ID); err != nil {
+		c.Log.Warnf("Failed find active session by token : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if session.UserID != claims.ID {
+		c.Log.Warnf("Session %s does not belong to user %s", session.ID, claims.ID)
+		return nil, fiber.ErrNotFound
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, claims.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
-	return &model.Auth{ID: claims.ID}, nil
+	return &model.Auth{ID: user.ID, SessionID: session.ID, Verified: user.VerifiedAt != 0}, nil
+}
+
+func (c *UserUseCase) verifyAccessToken(ctx context.Context, tx *gorm.DB, plain string) (*model.Auth, error) {
+	token, err := verifyAccessToken(ctx, tx, c.AccessTokenRepository, plain)
+	if err != nil {
+		c.Log.Warnf("Failed find access token : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, token.UserID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	return &model.Auth{
+		ID:            user.ID,
+		AccessTokenID: token.ID,
+		Scopes:        token.ScopeList(),
+		Verified:      user.VerifiedAt != 0,
+	}, nil
 }
 
 func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserRequest) (*model.UserResponse, error) {
@@ -106,6 +179,18 @@ func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 		return nil, fiber.ErrInternalServerError
 	}
 
+	// The account exists even if the mail fails, the user can ask for a resend
+	var code string
+	if err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		_, code, err = issueOtp(ctx, tx, c.OtpRepository, c.JWTService, user, entity.OtpPurposeEmailVerification)
+		return err
+	}); err != nil {
+		c.Log.Warnf("Failed to issue verification OTP : %+v", err)
+	} else if err := sendOtp(c.EmailClient, user.Email, code, entity.OtpPurposeEmailVerification); err != nil {
+		c.Log.Warnf("Failed to send verification OTP : %+v", err)
+	}
+
 	return converter.UserToResponse(user), nil
 }
 
@@ -118,27 +203,272 @@ func (c *UserUseCase) Login(ctx context.Context, request *model.LoginUserRequest
 		return nil, fiber.ErrBadRequest
 	}
 
+	keys := loginThrottleKeys(request.Email, request.IP)
+	if err := checkLoginThrottle(ctx, tx, c.LoginAttemptRepository, keys); err != nil {
+		c.Log.Warnf("Login throttled for %s : %+v", request.Email, err)
+		return nil, err
+	}
+
 	user := new(entity.User)
 	if err := c.UserRepository.FindByEmail(tx, user, request.Email); err != nil {
 		c.Log.Warnf("Failed find user by email : %+v", err)
-		return nil, fiber.ErrUnauthorized
+		return nil, c.failLogin(ctx, tx, keys)
 	}
 
 	if err := utils.ComparePassword(user.Password, request.Password); err != nil {
 		c.Log.Warnf("Failed to compare user password with bcrype hash : %+v", err)
+		return nil, c.failLogin(ctx, tx, keys)
+	}
+
+	if err := c.LoginAttemptRepository.DeleteByID(ctx, tx, keys[0].id); err != nil {
+		c.Log.Warnf("Failed reset login attempts : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return c.completeLogin(tx, user)
+}
+
+// LoginTwoFactor completes a login that Login answered with a challenge
+func (c *UserUseCase) LoginTwoFactor(ctx context.Context, request *model.LoginTwoFactorRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	challenge := new(entity.TwoFactorChallenge)
+	if err := c.ChallengeRepository.FindByHash(ctx, tx, challenge, utils.HashToken(request.ChallengeToken)); err != nil {
+		c.Log.Warnf("Failed find two-factor challenge : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	if challenge.ExpiresAt < time.Now().Unix() {
+		c.Log.Warnf("Two-factor challenge expired")
 		return nil, fiber.ErrUnauthorized
 	}
 
-	token, err := c.JWTService.GenerateToken(user, 24*time.Hour)
+	allowed, err := c.ChallengeRepository.ConsumeAttempt(ctx, tx, challenge.ID, maxChallengeAttempts)
 	if err != nil {
-		c.Log.Errorf("Failed to generate token : %+v", err)
+		c.Log.Warnf("Failed count two-factor attempt : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if !allowed {
+		c.Log.Warnf("Two-factor challenge %s has no attempts left", challenge.ID)
+		return nil, fiber.ErrTooManyRequests
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, challenge.UserID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	keys := loginThrottleKeys(user.Email, request.IP)
+	if err := checkLoginThrottle(ctx, tx, c.LoginAttemptRepository, keys); err != nil {
+		c.Log.Warnf("Login throttled for %s : %+v", user.Email, err)
+		return nil, err
+	}
+
+	ok, err := verifySecondFactor(ctx, tx, c.RecoveryCodeRepository, user, request.Code)
+	if err != nil {
+		c.Log.Warnf("Failed to verify second factor : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if !ok {
+		c.Log.Warnf("Invalid second factor for user %s", user.ID)
+		return nil, c.failLogin(ctx, tx, keys)
+	}
+
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.ChallengeRepository.Delete(tx, challenge); err != nil {
+		c.Log.Warnf("Failed delete two-factor challenge : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return c.startSession(tx, user)
+}
+
+// completeLogin answers a login with a two-factor challenge when the user has
+// TOTP enabled and starts a session otherwise. It commits tx.
+func (c *UserUseCase) completeLogin(tx *gorm.DB, user *entity.User) (*model.UserResponse, error) {
+	if user.TotpEnabledAt != 0 {
+		challengeToken, err := utils.GenerateOpaqueToken(32)
+		if err != nil {
+			c.Log.Errorf("Failed to generate challenge token : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+
+		challenge := &entity.TwoFactorChallenge{
+			ID:        uuid.New().String(),
+			TokenHash: utils.HashToken(challengeToken),
+			UserID:    user.ID,
+			ExpiresAt: time.Now().Add(twoFactorChallengeTTL).Unix(),
+		}
+
+		if err := c.ChallengeRepository.Create(tx, challenge); err != nil {
+			c.Log.Warnf("Failed create two-factor challenge to database : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+
+		return &model.UserResponse{TwoFactorRequired: true, ChallengeToken: challengeToken}, nil
+	}
+
+	return c.startSession(tx, user)
+}
+
+// failLogin records the failed attempt, commits it so the rollback of the
+// request does not discard it, and returns the error for the caller.
+func (c *UserUseCase) failLogin(ctx context.Context, tx *gorm.DB, keys []loginThrottleKey) error {
+	if err := recordLoginFailure(ctx, tx, c.LoginAttemptRepository, keys); err != nil {
+		c.Log.Warnf("Failed record login failure : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	return fiber.ErrUnauthorized
+}
+
+// startSession opens a new session for user, issues its first token pair and
+// commits tx.
+func (c *UserUseCase) startSession(tx *gorm.DB, user *entity.User) (*model.UserResponse, error) {
+	session := &entity.Session{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(refreshTokenTTL).UnixMilli(),
+	}
+
+	if err := c.SessionRepository.Create(tx, session); err != nil {
+		c.Log.Warnf("Failed create session to database : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.issueTokens(tx, user, session); err != nil {
+		c.Log.Errorf("Failed to issue tokens : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
-	user.Token = token
 
 	return converter.UserToTokenResponse(user), nil
 }
 
+func (c *UserUseCase) Refresh(ctx context.Context, request *model.RefreshUserRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	refreshToken := new(entity.RefreshToken)
+	if err := c.RefreshTokenRepository.FindByHash(ctx, tx, refreshToken, utils.HashToken(request.RefreshToken)); err != nil {
+		c.Log.Warnf("Failed find refresh token : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	rotated, err := c.RefreshTokenRepository.MarkRotated(ctx, tx, refreshToken.ID)
+	if err != nil {
+		c.Log.Warnf("Failed rotate refresh token : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if !rotated {
+		// A rotated token was presented again, so the family may be stolen.
+		c.Log.Warnf("Refresh token reuse detected, revoking session %s", refreshToken.SessionID)
+		if err := c.SessionRepository.RevokeByID(ctx, tx, refreshToken.SessionID); err != nil {
+			c.Log.Warnf("Failed revoke session : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		return nil, fiber.ErrUnauthorized
+	}
+
+	if refreshToken.ExpiresAt < time.Now().UnixMilli() {
+		c.Log.Warnf("Refresh token expired")
+		return nil, fiber.ErrUnauthorized
+	}
+
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindActive(ctx, tx, session, refreshToken.SessionID); err != nil {
+		c.Log.Warnf("Failed find active session : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, session.UserID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	session.ExpiresAt = time.Now().Add(refreshTokenTTL).UnixMilli()
+	if err := c.SessionRepository.Update(tx, session); err != nil {
+		c.Log.Warnf("Failed save session : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.issueTokens(tx, user, session); err != nil {
+		c.Log.Errorf("Failed to issue tokens : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.UserToTokenResponse(user), nil
+}
+
+// issueTokens creates a new refresh token in the session's family and signs a
+// short-lived access token for it, storing both on user.
+func (c *UserUseCase) issueTokens(tx *gorm.DB, user *entity.User, session *entity.Session) error {
+	refreshToken, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := c.RefreshTokenRepository.Create(tx, &entity.RefreshToken{
+		ID:        uuid.New().String(),
+		TokenHash: utils.HashToken(refreshToken),
+		SessionID: session.ID,
+		UserID:    user.ID,
+		ExpiresAt: session.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+
+	token, err := c.JWTService.GenerateSessionToken(user, session.ID, accessTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	user.Token = token
+	user.RefreshToken = refreshToken
+	return nil
+}
+
 func (c *UserUseCase) Current(ctx context.Context, request *model.GetUserRequest) (*model.UserResponse, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
@@ -177,10 +507,8 @@ func (c *UserUseCase) Logout(ctx context.Context, request *model.LogoutUserReque
 		return false, fiber.ErrNotFound
 	}
 
-	user.Token = ""
-
-	if err := c.UserRepository.Update(tx, user); err != nil {
-		c.Log.Warnf("Failed save user : %+v", err)
+	if err := c.SessionRepository.RevokeByID(ctx, tx, request.SessionID); err != nil {
+		c.Log.Warnf("Failed revoke session : %+v", err)
 		return false, fiber.ErrInternalServerError
 	}
 
@@ -211,6 +539,27 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 		user.Name = request.Name
 	}
 
+	// A new email is only staged here, ConfirmEmailChange swaps it in
+	var code string
+	if request.Email != "" && request.Email != user.Email {
+		exists, err := c.UserRepository.ExistsByEmail(tx, new(entity.User), request.Email)
+		if err != nil {
+			c.Log.WithError(err).Warn("Failed to check user existence by email")
+			return nil, fiber.ErrInternalServerError
+		}
+
+		if exists {
+			c.Log.Warnf("User already exists with email: %s", request.Email)
+			return nil, fiber.ErrConflict
+		}
+
+		user.PendingEmail = request.Email
+		if _, code, err = issueOtp(ctx, tx, c.OtpRepository, c.JWTService, user, entity.OtpPurposeEmailChange); err != nil {
+			c.Log.Warnf("Failed to issue %s OTP : %+v", entity.OtpPurposeEmailChange, err)
+			return nil, fiber.ErrInternalServerError
+		}
+	}
+
 	if err := c.UserRepository.Update(tx, user); err != nil {
 		c.Log.Warnf("Failed save user : %+v", err)
 		return nil, fiber.ErrInternalServerError
@@ -221,6 +570,103 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 		return nil, fiber.ErrInternalServerError
 	}
 
+	// Updating to the same email again issues a fresh code
+	if code != "" {
+		if err := sendOtp(c.EmailClient, user.PendingEmail, code, entity.OtpPurposeEmailChange); err != nil {
+			c.Log.Warnf("Failed to send %s OTP : %+v", entity.OtpPurposeEmailChange, err)
+			return nil, fiber.ErrInternalServerError
+		}
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+func (c *UserUseCase) ConfirmEmailChange(ctx context.Context, request *model.ConfirmEmailChangeRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if user.PendingEmail == "" {
+		c.Log.Warnf("No pending email change for user %s", user.ID)
+		return nil, fiber.ErrBadRequest
+	}
+
+	otp := new(entity.Otp)
+	if err := c.OtpRepository.FindByUserID(ctx, tx, otp, user.ID, entity.OtpPurposeEmailChange); err != nil {
+		c.Log.Warnf("Failed find OTP by user id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if otp.ExpiresAt < time.Now().Unix() {
+		c.Log.Warnf("OTP expired")
+		return nil, fiber.ErrBadRequest
+	}
+
+	allowed, err := c.OtpRepository.ConsumeAttempt(ctx, tx, otp.ID, maxOtpAttempts)
+	if err != nil {
+		c.Log.Errorf("Failed to count OTP attempt: %v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if !allowed {
+		c.Log.Warnf("OTP %s has no attempts left", otp.ID)
+		return nil, fiber.ErrTooManyRequests
+	}
+
+	if err := utils.ComparePassword(otp.Otp, request.Otp); err != nil {
+		c.Log.Warnf("Failed to compare OTP hash : %+v", err)
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		return nil, fiber.ErrUnauthorized
+	}
+
+	// The address may have been registered since the change was staged
+	exists, err := c.UserRepository.ExistsByEmail(tx, new(entity.User), user.PendingEmail)
+	if err != nil {
+		c.Log.WithError(err).Warn("Failed to check user existence by email")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if exists {
+		c.Log.Warnf("User already exists with email: %s", user.PendingEmail)
+		return nil, fiber.ErrConflict
+	}
+
+	oldEmail := user.Email
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	user.VerifiedAt = time.Now().UnixMilli()
+
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.OtpRepository.DeleteByUserIDAndPurpose(ctx, tx, user.ID, entity.OtpPurposeEmailChange); err != nil {
+		c.Log.Warnf("Failed delete OTP by user id : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.EmailClient.SendEmailChanged(oldEmail, user.Email); err != nil {
+		c.Log.Warnf("Failed to notify previous email address : %+v", err)
+	}
+
 	return converter.UserToResponse(user), nil
 }
 
@@ -233,11 +679,21 @@ func (c *UserUseCase) UpdatePassword(ctx context.Context, request *model.UpdateU
 	}
 
 	otp := new(entity.Otp)
-	if err := c.OtpRepository.FindByToken(ctx, tx, otp, request.Token); err != nil {
+	if err := c.OtpRepository.FindByToken(ctx, tx, otp, request.Token, entity.OtpPurposePasswordReset); err != nil {
 		c.Log.Warnf("Failed find OTP by token : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
 
+	if otp.VerifiedAt == 0 {
+		c.Log.Warnf("OTP not verified")
+		return nil, fiber.ErrForbidden
+	}
+
+	if otp.ExpiresAt < time.Now().Unix() {
+		c.Log.Warnf("OTP expired")
+		return nil, fiber.ErrBadRequest
+	}
+
 	request.ID = otp.UserID
 	user := new(entity.User)
 	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
@@ -264,6 +720,17 @@ func (c *UserUseCase) UpdatePassword(ctx context.Context, request *model.UpdateU
 		return nil, fiber.ErrInternalServerError
 	}
 
+	if err := c.SessionRepository.RevokeByUserID(ctx, tx, user.ID); err != nil {
+		c.Log.Warnf("Failed revoke sessions by user id : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// A leaked personal access token must not outlive the password
+	if err := c.AccessTokenRepository.DeleteByUserID(ctx, tx, user.ID); err != nil {
+		c.Log.Warnf("Failed revoke access tokens by user id : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError